@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kb-labs/create/internal/config"
+	"github.com/kb-labs/create/internal/installer"
+	"github.com/kb-labs/create/internal/logger"
+	"github.com/kb-labs/create/internal/manifest"
+)
+
+var flagAddPkgs []string
+
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a third-party plugin to an installed platform",
+	Long: `Installs community plugins that are not in the manifest by npm spec,
+records them in kb.config.json so update keeps them, and enables them
+in the project's .kb/kb.config.jsonc.
+
+Examples:
+  kb-create add --pkg @acme/kb-plugin-foo
+  kb-create add --pkg @acme/kb-plugin-foo@^1.2 --pkg kb-plugin-bar`,
+	Args: cobra.NoArgs,
+	RunE: runAdd,
+}
+
+func init() {
+	addCmd.Flags().StringArrayVar(&flagAddPkgs, "pkg", nil, "npm spec of the plugin to add (repeatable)")
+	_ = addCmd.MarkFlagRequired("pkg")
+	rootCmd.AddCommand(addCmd)
+	addPMFlag(addCmd)
+}
+
+func runAdd(cmd *cobra.Command, args []string) error {
+	out := newOutput()
+
+	platformDir, err := resolvePlatformDir(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Read(platformDir)
+	if err != nil {
+		return err
+	}
+	plugins, err := userPlugins(flagAddPkgs, &cfg.Manifest)
+	if err != nil {
+		return err
+	}
+
+	packageManager, err := platformPM(cfg, flagPM)
+	if err != nil {
+		return err
+	}
+
+	log, err := logger.New(platformDir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = log.Close() }()
+
+	sp := newSpinner()
+	ins := &installer.Installer{
+		PM:  packageManager,
+		Log: log,
+		OnStep: func(step, total int, label string) {
+			sp.setLabel(fmt.Sprintf("[%d/%d] %s", step, total, label))
+		},
+		OnLine:     sp.setDetail,
+		OnProgress: sp.setProgress,
+	}
+
+	ctx, stop := interruptible(cmd.Context())
+	defer stop()
+
+	sp.start()
+	result, err := ins.Add(ctx, platformDir, plugins)
+	sp.stop(err)
+	if err != nil && ctx.Err() != nil {
+		return interruptedError("add", platformDir)
+	}
+	if err != nil {
+		return fmt.Errorf("add failed: %w", err)
+	}
+
+	for _, id := range result.Unscaffolded {
+		out.Warn(fmt.Sprintf("could not find a plugins section in %s/.kb/kb.config.jsonc; add \"%s\": { \"enabled\": true } by hand", cfg.CWD, id))
+	}
+	out.OK(fmt.Sprintf("Added %d plugins (%s)", len(plugins), result.Duration.Round(100*time.Millisecond)))
+	return nil
+}
+
+// userPlugins turns npm specs from --plugin or --pkg into user plugin
+// components, rejecting duplicates and packages m already provides.
+func userPlugins(specs []string, m *manifest.Manifest) ([]manifest.Component, error) {
+	var out []manifest.Component
+	seen := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		c, err := m.UserPlugin(spec)
+		if err != nil {
+			return nil, err
+		}
+		if seen[c.ID] {
+			return nil, fmt.Errorf("plugin %s given more than once", c.ID)
+		}
+		seen[c.ID] = true
+		out = append(out, c)
+	}
+	return out, nil
+}
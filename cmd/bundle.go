@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kb-labs/create/internal/bundle"
+	"github.com/kb-labs/create/internal/installer"
+	"github.com/kb-labs/create/internal/logger"
+	"github.com/kb-labs/create/internal/wizard"
+)
+
+var (
+	flagBundleOut     string
+	flagBundlePreset  string
+	flagBundlePlugins []string
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Build an offline install bundle",
+	Long: `Downloads the manifest's default components (or a preset) with npm
+and writes them, with the lockfile and a snapshot of the manifest, to a
+single archive. Install it on a machine without network access with
+kb-create --offline --bundle <file>.
+
+Build the bundle on the same OS and architecture as the target machines:
+packages with native binaries only download the ones for this machine.
+
+Examples:
+  kb-create bundle --out kb-platform-1.0.0.tgz
+  kb-create bundle --preset ai-full --plugin @acme/kb-plugin-foo@^1`,
+	Args: cobra.NoArgs,
+	RunE: runBundle,
+}
+
+func init() {
+	bundleCmd.Flags().StringVar(&flagBundleOut, "out", "", "bundle file to write (default: kb-platform-<manifest version>.tgz)")
+	bundleCmd.Flags().StringVar(&flagBundlePreset, "preset", "", "bundle a named component preset instead of the defaults")
+	bundleCmd.Flags().StringArrayVar(&flagBundlePlugins, "plugin", nil, "also bundle a third-party plugin by npm spec (repeatable)")
+	addManifestFlags(bundleCmd)
+	rootCmd.AddCommand(bundleCmd)
+}
+
+func runBundle(cmd *cobra.Command, args []string) error {
+	out := newOutput()
+
+	m, err := loadManifest(flagManifest, nil, flagChannel, manifestOverlays(nil))
+	if err != nil {
+		return err
+	}
+	plugins, err := userPlugins(flagBundlePlugins, m)
+	if err != nil {
+		return err
+	}
+	sel, err := wizard.Run(m, wizard.WizardOptions{Yes: true, Preset: flagBundlePreset, UserPlugins: plugins})
+	if err != nil {
+		return err
+	}
+
+	path := flagBundleOut
+	if path == "" {
+		path = fmt.Sprintf("kb-platform-%s.tgz", m.Version)
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return err
+	}
+
+	// Packages are installed into a scratch platform dir whose npm cache
+	// becomes the bundle. It is kept on failure so the log can be read.
+	staging, err := os.MkdirTemp("", "kb-bundle-*")
+	if err != nil {
+		return err
+	}
+	sel.PlatformDir = staging
+	packageManager, err := bundlePM("", filepath.Join(staging, bundle.CacheDir), false)
+	if err != nil {
+		_ = os.RemoveAll(staging)
+		return err
+	}
+
+	log, err := logger.New(staging)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = log.Close() }()
+
+	sp := newSpinner()
+	ins := &installer.Installer{
+		PM:  packageManager,
+		Log: log,
+		OnStep: func(step, total int, label string) {
+			sp.setLabel(fmt.Sprintf("[%d/%d] %s", step, total, label))
+		},
+		OnLine:     sp.setDetail,
+		OnProgress: sp.setProgress,
+	}
+
+	ctx, stop := interruptible(cmd.Context())
+	defer stop()
+
+	fmt.Println()
+	sp.start()
+	result, err := ins.Bundle(ctx, sel, m, path, bundle.NewMeta(installerVersion))
+	sp.stop(err)
+	if err != nil && ctx.Err() != nil {
+		return fmt.Errorf("bundle interrupted; %s was not written", path)
+	}
+	if err != nil {
+		return fmt.Errorf("bundle failed: %w\nLog: %s", err, log.LogPath())
+	}
+	_ = os.RemoveAll(staging)
+
+	size := ""
+	if info, err := os.Stat(result.Path); err == nil {
+		size = fmt.Sprintf(", %.1f MB", float64(info.Size())/(1<<20))
+	}
+	out.OK(fmt.Sprintf("Wrote %s (%d packages%s, %s)", result.Path, len(result.Packages), size, result.Duration.Round(100*time.Millisecond)))
+	out.Info("Install it offline with: kb-create --offline --bundle " + filepath.Base(result.Path))
+	return nil
+}
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"crypto/ed25519"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,6 +13,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 
+	"github.com/kb-labs/create/internal/bundle"
 	"github.com/kb-labs/create/internal/config"
 	"github.com/kb-labs/create/internal/installer"
 	"github.com/kb-labs/create/internal/logger"
@@ -20,14 +23,55 @@ import (
 	"github.com/kb-labs/create/internal/wizard"
 )
 
+const (
+	// manifestEnv names the env var used when --manifest is not given.
+	manifestEnv = "KB_MANIFEST"
+	// manifestKeysEnv holds extra comma-separated base64 ed25519 public keys.
+	manifestKeysEnv = "KB_MANIFEST_KEYS"
+	// manifestOverlaysEnv holds comma-separated overlay files or URLs used
+	// when --manifest-overlay is not given.
+	manifestOverlaysEnv = "KB_MANIFEST_OVERLAYS"
+	// upgradeHint tells the user how to replace an outdated binary.
+	upgradeHint = `Upgrade kb-create, then run the command again:
+  curl -fsSL https://raw.githubusercontent.com/KirillBaranov/kb-labs-create/main/install.sh | sh
+or download the latest release from https://github.com/KirillBaranov/kb-labs-create/releases/latest`
+)
+
 var (
-	flagYes      bool
-	flagPlatform string
+	flagYes              bool
+	flagPlatform         string
+	flagManifest         string
+	flagManifestKeys     []string
+	flagManifestOverlays []string
+	flagInsecureManifest bool
+	flagChannel          string
+	flagPreset           string
+	flagPorts            []string
+	flagPlugins          []string
+	flagPM               string
+	flagOffline          bool
+	flagBundle           string
 )
 
 func init() {
 	rootCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "skip wizard and install with defaults")
 	rootCmd.Flags().StringVar(&flagPlatform, "platform", "", "platform installation directory")
+	rootCmd.Flags().StringVar(&flagPreset, "preset", "", "install a named component preset from the manifest (e.g. minimal, ai-full)")
+	rootCmd.Flags().StringArrayVar(&flagPorts, "port", nil, "override a service port, e.g. rest=5051 (repeatable)")
+	rootCmd.Flags().StringVar(&flagPM, "pm", "", "package manager: pnpm, yarn, bun or npm (env: "+pmEnv+"; default: auto-detect)")
+	rootCmd.Flags().StringArrayVar(&flagPlugins, "plugin", nil, "also install a third-party plugin by npm spec, e.g. @acme/kb-plugin-foo@^1 (repeatable)")
+	rootCmd.Flags().BoolVar(&flagOffline, "offline", false, "install without network access; requires --bundle")
+	rootCmd.Flags().StringVar(&flagBundle, "bundle", "", "install from the offline bundle `file` built by kb-create bundle (implies --offline)")
+	addManifestFlags(rootCmd)
+}
+
+// addManifestFlags registers the manifest source flags shared by create and update.
+func addManifestFlags(c *cobra.Command) {
+	c.Flags().StringVar(&flagManifest, "manifest", "", "manifest URL or file path (env: "+manifestEnv+")")
+	c.Flags().StringArrayVar(&flagManifestOverlays, "manifest-overlay", nil, "partial manifest file or URL merged on top of the base one; repeatable (env: "+manifestOverlaysEnv+")")
+	c.Flags().StringArrayVar(&flagManifestKeys, "manifest-key", nil, "extra trusted base64 ed25519 key for remote manifests (env: "+manifestKeysEnv+")")
+	c.Flags().BoolVar(&flagInsecureManifest, "insecure-manifest", false, "accept unsigned or badly signed remote manifests")
+	c.Flags().StringVar(&flagChannel, "channel", "", "release channel: stable, beta or nightly")
 }
 
 func runCreate(cmd *cobra.Command, args []string) error {
@@ -45,21 +89,72 @@ func runCreate(cmd *cobra.Command, args []string) error {
 	tc, tcfg := initTelemetry(cmd.Root().Version)
 	defer tc.Flush()
 
-	// Load manifest (embedded for now).
-	m, err := manifest.LoadDefault()
+	var (
+		m              *manifest.Manifest
+		b              *bundle.Bundle
+		packageManager pm.PackageManager
+		err            error
+	)
+	if flagBundle != "" {
+		dir, err := os.MkdirTemp("", "kb-bundle-*")
+		if err != nil {
+			return err
+		}
+		defer func() { _ = os.RemoveAll(dir) }()
+		if b, err = openBundle(flagBundle, dir); err != nil {
+			return err
+		}
+		m = b.Manifest
+		if packageManager, err = bundlePM(flagPM, b.CacheDir(), true); err != nil {
+			return err
+		}
+	} else {
+		if flagOffline {
+			return fmt.Errorf("--offline needs --bundle <file>; build one with kb-create bundle on a machine with network access")
+		}
+		if m, err = loadManifest(flagManifest, nil, flagChannel, manifestOverlays(nil)); err != nil {
+			return err
+		}
+		if packageManager, err = installPM(flagPM); err != nil {
+			return err
+		}
+	}
+
+	host := manifest.CurrentHost(pm.NodeVersion())
+	ports, err := parsePortFlags(flagPorts, m)
 	if err != nil {
-		return fmt.Errorf("load manifest: %w", err)
+		return err
+	}
+	userPlugins, err := userPlugins(flagPlugins, m)
+	if err != nil {
+		return err
 	}
 
-	// Show wizard or use defaults.
+	// Show wizard or use defaults. A bundle fixes the components, so only
+	// the directories are needed.
 	sel, err := wizard.Run(m, wizard.WizardOptions{
-		Yes:                flagYes,
+		Yes:                flagYes || b != nil,
 		DefaultProjectCWD:  projectCWD,
 		DefaultPlatformDir: flagPlatform,
+		Preset:             flagPreset,
+		Ports:              ports,
+		UserPlugins:        userPlugins,
+		Host:               &host,
 	})
 	if err != nil {
 		return err // includes "cancelled"
 	}
+	if b != nil {
+		sel.Services, sel.Plugins, sel.UserPlugins = b.Meta.Services, b.Meta.Plugins, b.Meta.UserPlugins
+		sel.Bundle = b
+	}
+
+	// A bound port doesn't stop the install (the service isn't started yet),
+	// but the user should know before the platform tries to use it.
+	out := newOutput()
+	for _, p := range busyPorts(m.ServicePorts(sel.Services, sel.Ports)) {
+		out.Warn(fmt.Sprintf("port %d (%s) is already in use; choose another with --port %s=<port>", p.Port, p.Key, p.Key))
+	}
 
 	// Attach telemetry config so it gets persisted in kb.config.json.
 	sel.Telemetry = tcfg
@@ -78,31 +173,40 @@ func runCreate(cmd *cobra.Command, args []string) error {
 
 	fmt.Println()
 
-	packageManager := pm.Detect()
 	log.Printf("Using %s", packageManager.Name())
 
 	tc.Set("pm", packageManager.Name())
 	tc.Set("services", strings.Join(sel.Services, ","))
 	tc.Set("plugins", strings.Join(sel.Plugins, ","))
+	tc.Set("preset", flagPreset)
 	tc.Track("install_started", nil)
 
 	sp := newSpinner()
 
 	ins := &installer.Installer{
-		PM:  packageManager,
-		Log: log,
+		PM:   packageManager,
+		Log:  log,
+		Host: &host,
 		OnStep: func(step, total int, label string) {
 			sp.setLabel(fmt.Sprintf("[%d/%d] %s", step, total, label))
 		},
 		OnLine: func(line string) {
 			sp.setDetail(line)
 		},
+		OnProgress: sp.setProgress,
 	}
 
+	ctx, stop := interruptible(cmd.Context())
+	defer stop()
+
 	sp.start()
-	result, err := ins.Install(sel, m)
+	result, err := ins.Install(ctx, sel, m)
 	sp.stop(err)
 
+	if err != nil && ctx.Err() != nil {
+		tc.Track("install_interrupted", nil)
+		return interruptedError("installation", sel.PlatformDir)
+	}
 	if err != nil {
 		tc.Track("install_failed", map[string]string{"error": err.Error()})
 		return fmt.Errorf("installation failed: %w", err)
@@ -116,6 +220,145 @@ func runCreate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// openBundle extracts the offline bundle at file into dir. Its manifest and
+// components replace the manifest and selection flags, so those are refused.
+func openBundle(file, dir string) (*bundle.Bundle, error) {
+	if flagManifest != "" || len(flagManifestOverlays) > 0 || flagChannel != "" || flagPreset != "" || len(flagPlugins) > 0 {
+		return nil, fmt.Errorf("--bundle installs the manifest and components inside the bundle; drop --manifest, --manifest-overlay, --channel, --preset and --plugin")
+	}
+	b, err := bundle.Open(file, dir, installerVersion)
+	var verr *manifest.InstallerVersionError
+	if errors.As(err, &verr) {
+		return nil, fmt.Errorf("%w\n\n%s", err, upgradeHint)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := b.CheckHost(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// loadManifest picks the manifest source from the --manifest value, then
+// KB_MANIFEST, then the source recorded in cfg by an earlier install (nil for
+// a fresh one), and loads it, switching to channel if set and merging
+// overlays on top. Keys recorded in cfg stay trusted; --insecure-manifest
+// has to be passed again on every run.
+// Fallback warnings are printed so remote outages are visible.
+func loadManifest(value string, cfg *config.PlatformConfig, channel string, overlays []string) (*manifest.Manifest, error) {
+	out := newOutput()
+	keys, err := trustedManifestKeys()
+	if err != nil {
+		return nil, err
+	}
+	opts := manifest.LoadOptions{
+		TrustedKeys:      keys,
+		Insecure:         flagInsecureManifest,
+		Channel:          channel,
+		CacheDir:         manifest.DefaultCacheDir(),
+		InstallerVersion: installerVersion,
+		Overlays:         overlays,
+		OnWarn:           out.Warn,
+	}
+
+	var prev manifest.Source
+	if cfg != nil {
+		prev = baseSource(cfg)
+		opts.TrustedKeys = append(opts.TrustedKeys, cfg.ManifestKeys...)
+	}
+	if value == "" {
+		value = os.Getenv(manifestEnv)
+	}
+	switch {
+	case manifest.IsRemote(value):
+		opts.RemoteURL = value
+	case value != "":
+		// An explicitly requested file must exist — never fall back silently.
+		if _, err := os.Stat(value); err != nil {
+			return nil, fmt.Errorf("manifest %s: %w", value, err)
+		}
+		// The path is recorded for update, which may run from anywhere.
+		abs, err := filepath.Abs(value)
+		if err != nil {
+			return nil, fmt.Errorf("manifest %s: %w", value, err)
+		}
+		opts.LocalOverride = abs
+	case prev.Kind == manifest.SourceRemote, prev.Kind == manifest.SourceCache:
+		// A cached copy was loaded for a remote URL that was unreachable at
+		// the time; try that URL again.
+		opts.RemoteURL = prev.Location
+	case prev.Kind == manifest.SourceOverride:
+		opts.LocalOverride = prev.Location
+	case prev.Kind == manifest.SourceBundle:
+		// The embedded manifest would describe other packages, and npm would
+		// go online without the bundle's cache.
+		return nil, fmt.Errorf("the platform was installed from the offline bundle %s\n"+
+			"Pass --manifest <url|file> to update from a manifest over the network, or install a newer bundle with kb-create --bundle <file>", prev.Location)
+	}
+
+	m, err := manifest.Load(opts)
+	var verr *manifest.InstallerVersionError
+	if errors.As(err, &verr) {
+		return nil, fmt.Errorf("%w\n\n%s", err, upgradeHint)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load manifest: %w", err)
+	}
+	return m, nil
+}
+
+// baseSource returns the manifest source later loads of cfg's platform start
+// from: the base manifest its channel was looked up in, if recorded, else the
+// manifest it was installed from.
+func baseSource(cfg *config.PlatformConfig) manifest.Source {
+	if cfg.ManifestBase.Kind != "" {
+		return cfg.ManifestBase
+	}
+	return cfg.ManifestSource
+}
+
+// manifestOverlays picks overlay locations from --manifest-overlay, then
+// KB_MANIFEST_OVERLAYS, then prev (the overlays recorded by an earlier install).
+func manifestOverlays(prev []manifest.Source) []string {
+	if len(flagManifestOverlays) > 0 {
+		return flagManifestOverlays
+	}
+	var locs []string
+	for _, o := range strings.Split(os.Getenv(manifestOverlaysEnv), ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			locs = append(locs, o)
+		}
+	}
+	if len(locs) > 0 {
+		return locs
+	}
+	for _, src := range prev {
+		locs = append(locs, src.Location)
+	}
+	return locs
+}
+
+// trustedManifestKeys parses user-supplied keys from --manifest-key and
+// KB_MANIFEST_KEYS. Keys embedded in the binary are added by manifest.Load.
+func trustedManifestKeys() ([]ed25519.PublicKey, error) {
+	raw := append([]string{}, flagManifestKeys...)
+	for _, k := range strings.Split(os.Getenv(manifestKeysEnv), ",") {
+		if strings.TrimSpace(k) != "" {
+			raw = append(raw, k)
+		}
+	}
+	keys := make([]ed25519.PublicKey, 0, len(raw))
+	for _, k := range raw {
+		pub, err := manifest.ParsePublicKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("manifest key: %w", err)
+		}
+		keys = append(keys, pub)
+	}
+	return keys, nil
+}
+
 // initTelemetry resolves consent and returns a ready-to-use Client plus the
 // TelemetryConfig to be persisted in kb.config.json. In non-interactive mode
 // (--yes) we never prompt — if no prior consent exists telemetry stays off.
@@ -151,19 +394,33 @@ func initTelemetry(version string) (*telemetry.Client, config.TelemetryConfig) {
 // ── spinner ───────────────────────────────────────────────────────────────────
 
 // spinner renders a rotating indicator with a label and a detail line
-// that updates in-place while the install is running.
+// that updates in-place while the install is running. The detail line shows
+// package counts when the package manager reports them, else its last line.
 type spinner struct {
-	done   chan struct{}
-	label  string
-	detail string
-	mu     sync.Mutex
+	done     chan struct{}
+	label    string
+	detail   string
+	packages *packageProgress
+	mu       sync.Mutex
 }
 
-func newSpinner() *spinner { return &spinner{done: make(chan struct{})} }
+func newSpinner() *spinner {
+	return &spinner{done: make(chan struct{}), packages: newPackageProgress(time.Now)}
+}
 
+// setLabel starts a new stage, resetting the package counts.
 func (s *spinner) setLabel(l string) {
 	s.mu.Lock()
 	s.label = l
+	s.detail = ""
+	s.packages = newPackageProgress(time.Now)
+	s.mu.Unlock()
+}
+
+// setProgress records a per-package event.
+func (s *spinner) setProgress(p pm.Progress) {
+	s.mu.Lock()
+	s.packages.record(p)
 	s.mu.Unlock()
 }
 
@@ -192,6 +449,9 @@ func (s *spinner) start() {
 				s.mu.Lock()
 				label := s.label
 				detail := s.detail
+				if !s.packages.empty() {
+					detail = s.packages.String()
+				}
 				s.mu.Unlock()
 
 				frame := frames[i%len(frames)]
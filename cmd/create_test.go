@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kb-labs/create/internal/config"
+	"github.com/kb-labs/create/internal/manifest"
+)
+
+// testManifest is a minimal valid manifest.
+const testManifest = `{"version":"1.0.0","core":[{"name":"@kb-labs/cli-bin"}]}`
+
+// writeManifest writes testManifest to dir/name and returns its path.
+func writeManifest(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(testManifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestLoadManifestRecordsAbsolutePath verifies that a relative --manifest
+// path is recorded as an absolute one, so update finds it from anywhere.
+func TestLoadManifestRecordsAbsolutePath(t *testing.T) {
+	dir := t.TempDir()
+	writeManifest(t, dir, "manifest.json")
+	t.Chdir(dir)
+	t.Setenv(manifestEnv, "")
+
+	m, err := loadManifest("manifest.json", nil, "", nil)
+	if err != nil {
+		t.Fatalf("loadManifest() error = %v", err)
+	}
+	want := manifest.Source{Kind: manifest.SourceOverride, Location: filepath.Join(dir, "manifest.json")}
+	if m.Source != want {
+		t.Errorf("Source = %+v, want %+v", m.Source, want)
+	}
+}
+
+// TestLoadManifestRetriesCachedSource verifies that a platform installed
+// from the cached copy of a remote manifest goes back to its URL.
+func TestLoadManifestRetriesCachedSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testManifest))
+	}))
+	defer srv.Close()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv(manifestEnv, "")
+
+	flagInsecureManifest = true
+	defer func() { flagInsecureManifest = false }()
+
+	cfg := &config.PlatformConfig{ManifestSource: manifest.Source{Kind: manifest.SourceCache, Location: srv.URL}}
+	m, err := loadManifest("", cfg, "", nil)
+	if err != nil {
+		t.Fatalf("loadManifest() error = %v", err)
+	}
+	want := manifest.Source{Kind: manifest.SourceRemote, Location: srv.URL}
+	if m.Source != want {
+		t.Errorf("Source = %+v, want %+v", m.Source, want)
+	}
+}
+
+// TestLoadManifestTrustsRecordedKeys verifies that a manifest signed with a
+// key supplied at install time still verifies on later loads without the
+// key being passed again.
+func TestLoadManifestTrustsRecordedKeys(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(testManifest)))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, manifest.SignatureSuffix) {
+			_, _ = w.Write([]byte(sig))
+			return
+		}
+		_, _ = w.Write([]byte(testManifest))
+	}))
+	defer srv.Close()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv(manifestEnv, "")
+	t.Setenv(manifestKeysEnv, "")
+
+	cfg := &config.PlatformConfig{ManifestSource: manifest.Source{Kind: manifest.SourceRemote, Location: srv.URL + "/manifest.json"}}
+	if _, err := loadManifest("", cfg, "", nil); !errors.Is(err, manifest.ErrSignature) {
+		t.Fatalf("loadManifest() without keys error = %v, want ErrSignature", err)
+	}
+
+	cfg.ManifestKeys = []ed25519.PublicKey{pub}
+	m, err := loadManifest("", cfg, "", nil)
+	if err != nil {
+		t.Fatalf("loadManifest() error = %v", err)
+	}
+	if len(m.TrustedKeys) != 1 || !m.TrustedKeys[0].Equal(pub) {
+		t.Errorf("TrustedKeys = %v, want the recorded key", m.TrustedKeys)
+	}
+}
+
+// TestLoadManifestRefusesBundleSource verifies that a platform installed
+// from an offline bundle needs an explicit manifest, and accepts one.
+func TestLoadManifestRefusesBundleSource(t *testing.T) {
+	t.Setenv(manifestEnv, "")
+	prev := &config.PlatformConfig{ManifestSource: manifest.Source{Kind: manifest.SourceBundle, Location: "/tmp/kb.tar.gz"}}
+	if _, err := loadManifest("", prev, "", nil); err == nil || !strings.Contains(err.Error(), "--manifest") {
+		t.Errorf("loadManifest() error = %v, want a hint to pass --manifest", err)
+	}
+
+	path := writeManifest(t, t.TempDir(), "manifest.json")
+	if _, err := loadManifest(path, prev, "", nil); err != nil {
+		t.Errorf("loadManifest(%s) error = %v", path, err)
+	}
+}
+
+// TestLoadManifestSwitchesChannelFromBase verifies that channels are looked
+// up in the platform's recorded base manifest in both directions, rather
+// than in the embedded one.
+func TestLoadManifestSwitchesChannelFromBase(t *testing.T) {
+	t.Setenv(manifestEnv, "")
+	dir := t.TempDir()
+	beta := filepath.Join(dir, "beta.json")
+	if err := os.WriteFile(beta, []byte(`{"version":"2.0.0-beta.1","channel":"beta","core":[{"name":"@kb-labs/cli-bin"}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	base := filepath.Join(dir, "stable.json")
+	if err := os.WriteFile(base, []byte(`{"version":"1.0.0","channels":{"beta":"`+beta+`"},"core":[{"name":"@kb-labs/cli-bin"}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.PlatformConfig{ManifestSource: manifest.Source{Kind: manifest.SourceOverride, Location: base}}
+	m, err := loadManifest("", cfg, "beta", nil)
+	if err != nil {
+		t.Fatalf("loadManifest(beta) error = %v", err)
+	}
+	if m.Source.Location != beta || m.BaseSource.Location != base {
+		t.Errorf("Source = %v, BaseSource = %v, want beta from %s", m.Source, m.BaseSource, base)
+	}
+
+	cfg = &config.PlatformConfig{ManifestSource: m.Source, ManifestBase: m.BaseSource}
+	m, err = loadManifest("", cfg, manifest.DefaultChannel, nil)
+	if err != nil {
+		t.Fatalf("loadManifest(stable) error = %v", err)
+	}
+	if m.Source.Location != base {
+		t.Errorf("Source = %v, want %s", m.Source, base)
+	}
+}
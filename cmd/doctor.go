@@ -10,6 +10,10 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/kb-labs/create/internal/config"
+	"github.com/kb-labs/create/internal/manifest"
+	"github.com/kb-labs/create/internal/pm"
 )
 
 type doctorCheck struct {
@@ -27,16 +31,33 @@ var doctorCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(doctorCmd)
+	addManifestFlags(doctorCmd)
 }
 
 func runDoctor(cmd *cobra.Command, args []string) error {
 	out := newOutput()
+	// A manifest that can't be loaded is a finding like any other; the
+	// remaining checks run against the embedded manifest.
+	m, ports, err := doctorManifest(cmd)
+	manifestCheck := doctorCheck{Name: "manifest", OK: err == nil}
+	if err != nil {
+		manifestCheck.Details = err.Error() + " (checking against the embedded manifest)"
+		if m, err = manifest.LoadDefault(); err != nil {
+			return err
+		}
+	} else {
+		manifestCheck.Details = fmt.Sprintf("%s from %s", m.Version, m.Source)
+	}
+	host := manifest.CurrentHost(pm.NodeVersion())
+
 	checks := []doctorCheck{
 		checkPath(),
 		checkBinary("node", "--version"),
 		checkBinary("git", "--version"),
 		checkBinary("docker", "--version"),
 		checkNetwork(),
+		manifestCheck,
+		checkPlatformConstraints(m, host),
 	}
 
 	okCount := 0
@@ -50,6 +71,9 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	printComponentConstraints(out, m, host)
+	printServicePorts(out, m.ServicePorts(nil, ports))
+
 	fmt.Println()
 	summary := fmt.Sprintf("Doctor summary: %d/%d checks passed", okCount, len(checks))
 	if okCount != len(checks) {
@@ -121,6 +145,84 @@ func checkNetwork() doctorCheck {
 	return doctorCheck{Name: "network", OK: true, Details: fmt.Sprintf("github.com reachable (%d)", resp.StatusCode)}
 }
 
+// doctorManifest loads the manifest of the installed platform, if there is
+// one, so constraints are checked against what will actually be updated.
+// The manifest flags take precedence, as for update. It also returns the
+// platform's port overrides.
+func doctorManifest(cmd *cobra.Command) (*manifest.Manifest, map[string]int, error) {
+	if platformDir, err := resolvePlatformDir(cmd); err == nil {
+		if cfg, err := config.Read(platformDir); err == nil {
+			// An offline platform is checked against the bundle it came from.
+			if cfg.ManifestSource.Kind == manifest.SourceBundle && flagManifest == "" && os.Getenv(manifestEnv) == "" {
+				return &cfg.Manifest, cfg.Ports, nil
+			}
+			channel := cfg.Channel
+			if flagChannel != "" {
+				channel = flagChannel
+			}
+			m, err := loadManifest(flagManifest, cfg, channel, manifestOverlays(cfg.ManifestOverlays))
+			return m, cfg.Ports, err
+		}
+	}
+	m, err := loadManifest(flagManifest, nil, flagChannel, manifestOverlays(nil))
+	return m, nil, err
+}
+
+func checkPlatformConstraints(m *manifest.Manifest, h manifest.Host) doctorCheck {
+	if m.Constraints.IsZero() {
+		return doctorCheck{Name: "platform", OK: true, Details: "no host constraints"}
+	}
+	if err := m.Constraints.Check(h); err != nil {
+		return doctorCheck{Name: "platform", OK: false, Details: err.Error()}
+	}
+	return doctorCheck{Name: "platform", OK: true, Details: m.Constraints.String()}
+}
+
+// printComponentConstraints lists components with host constraints. An
+// unsupported component is a warning, not a failed check: it is simply
+// unavailable for selection on this machine.
+func printComponentConstraints(out output, m *manifest.Manifest, h manifest.Host) {
+	var constrained []manifest.Component
+	for _, c := range append(append([]manifest.Component{}, m.Services...), m.Plugins...) {
+		if !c.Constraints.IsZero() {
+			constrained = append(constrained, c)
+		}
+	}
+	if len(constrained) == 0 {
+		return
+	}
+	node := h.Node
+	if node == "" {
+		node = "not found"
+	}
+	fmt.Println()
+	out.Section(fmt.Sprintf("Component Constraints (%s/%s, node %s)", h.OS, h.Arch, node))
+	for _, c := range constrained {
+		if err := c.Constraints.Check(h); err != nil {
+			out.Warn(fmt.Sprintf("%-12s %s", c.ID, err))
+		} else {
+			out.OK(fmt.Sprintf("%-12s %s", c.ID, c.Constraints))
+		}
+	}
+}
+
+// printServicePorts reports which service ports are already bound on
+// localhost. A busy port is a warning: it may be the platform's own service.
+func printServicePorts(out output, ports []manifest.ServicePort) {
+	if len(ports) == 0 {
+		return
+	}
+	fmt.Println()
+	out.Section("Service Ports")
+	for _, p := range ports {
+		if portInUse(p.Port) {
+			out.Warn(fmt.Sprintf("%-12s %d in use (already running, or change it with --port %s=<port>)", p.Key, p.Port, p.Key))
+		} else {
+			out.OK(fmt.Sprintf("%-12s %d free", p.Key, p.Port))
+		}
+	}
+}
+
 func firstLine(s string) string {
 	if s == "" {
 		return ""
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// interruptible returns a context cancelled by SIGINT or SIGTERM. Install
+// it only around package manager work so Ctrl+C at a prompt still exits
+// immediately.
+func interruptible(parent context.Context) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+}
+
+// interruptedError explains how to recover from an interrupted operation.
+// The installer writes kb.config.json last, so it still describes the last
+// completed install or update.
+func interruptedError(op, platformDir string) error {
+	return fmt.Errorf("%s interrupted; the configuration in %s was not changed.\n"+
+		"Run the same command again to finish. The package manager repairs a partially written node_modules", op, platformDir)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kb-labs/create/internal/manifest"
+)
+
+var manifestCmd = &cobra.Command{
+	Use:   "manifest",
+	Short: "Inspect and check platform manifests",
+}
+
+var manifestValidateCmd = &cobra.Command{
+	Use:   "validate <file>",
+	Short: "Validate a manifest file",
+	Long: `Checks a manifest JSON file and reports every problem with its JSON path.
+Exits non-zero when the manifest is invalid, so it can gate CI pipelines.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runManifestValidate,
+}
+
+func init() {
+	manifestCmd.AddCommand(manifestValidateCmd)
+	rootCmd.AddCommand(manifestCmd)
+}
+
+func runManifestValidate(cmd *cobra.Command, args []string) error {
+	out := newOutput()
+	path := args[0]
+
+	// #nosec G304 -- path is an explicit CLI argument.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read manifest: %w", err)
+	}
+
+	m, err := manifest.Parse(data)
+	var verr *manifest.ValidationError
+	if errors.As(err, &verr) {
+		for _, p := range verr.Problems {
+			out.Err(p.String())
+		}
+		fmt.Println()
+		return fmt.Errorf("%s: %d problems found", path, len(verr.Problems))
+	}
+	if err != nil {
+		return err
+	}
+
+	out.OK(fmt.Sprintf("%s is valid (version %s, %d core, %d services, %d plugins)",
+		path, m.Version, len(m.Core), len(m.Services), len(m.Plugins)))
+	return nil
+}
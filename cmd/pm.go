@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kb-labs/create/internal/config"
+	"github.com/kb-labs/create/internal/pm"
+)
+
+// pmEnv names the env var used when --pm is not given.
+const pmEnv = "KB_PM"
+
+// installPM returns the package manager for a new install: --pm, then KB_PM,
+// then whatever pm.Detect finds.
+func installPM(flag string) (pm.PackageManager, error) {
+	name, from := flag, "--pm"
+	if name == "" {
+		name, from = os.Getenv(pmEnv), pmEnv
+	}
+	if name == "" {
+		return pm.Detect(), nil
+	}
+	m, err := pm.New(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", from, err)
+	}
+	return m, nil
+}
+
+// platformPM returns the package manager the platform was installed with.
+// flag is the --pm value; it and KB_PM pick the manager for configs written
+// before the manager was recorded. Switching managers on an existing
+// node_modules breaks it, so naming a different one than the recorded one
+// is an error rather than silently ignored.
+func platformPM(cfg *config.PlatformConfig, flag string) (pm.PackageManager, error) {
+	if cfg.PM == "" {
+		return installPM(flag)
+	}
+	name, from := flag, "--pm"
+	if name == "" {
+		name, from = os.Getenv(pmEnv), pmEnv
+	}
+	if name != "" && name != cfg.PM {
+		return nil, fmt.Errorf("%s %s: platform %s was installed with %s, and switching package managers breaks node_modules\nDrop %s, or reinstall the platform with --pm %s", from, name, cfg.Platform, cfg.PM, from, name)
+	}
+	m, err := pm.New(cfg.PM)
+	if err != nil {
+		return nil, fmt.Errorf("platform %s was installed with %s, which is no longer available: %w\nInstall %s again, or reinstall the platform with another --pm", cfg.Platform, cfg.PM, err, cfg.PM)
+	}
+	return m, nil
+}
+
+// addPMFlag registers --pm on the commands that work on an installed
+// platform, where it must match the recorded manager.
+func addPMFlag(c *cobra.Command) {
+	c.Flags().StringVar(&flagPM, "pm", "", "package manager of platforms installed before it was recorded; must match the recorded one (env: "+pmEnv+")")
+}
+
+// bundlePM returns the npm manager for offline bundles with its cache at
+// cache: bundles are built by filling that cache, and installed from it
+// alone when offline is set. flag is the --pm value, which must be npm.
+func bundlePM(flag, cache string, offline bool) (pm.PackageManager, error) {
+	if flag != "" && flag != "npm" {
+		return nil, fmt.Errorf("--pm %s: offline bundles are built and installed with npm", flag)
+	}
+	if _, err := pm.New("npm"); err != nil {
+		return nil, fmt.Errorf("offline bundles need npm: %w", err)
+	}
+	return &pm.NpmManager{Cache: cache, Offline: offline}, nil
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kb-labs/create/internal/config"
+)
+
+// pathWith replaces PATH with a dir holding empty executables named bins.
+func pathWith(t *testing.T, bins ...string) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, b := range bins {
+		// #nosec G306 -- fake binaries must be executable.
+		if err := os.WriteFile(filepath.Join(dir, b), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestInstallPM(t *testing.T) {
+	pathWith(t, "node", "npm", "pnpm", "yarn")
+	t.Setenv(pmEnv, "")
+
+	cases := []struct{ flag, env, want string }{
+		{"", "", "pnpm"},
+		{"", "yarn", "yarn"},
+		{"npm", "yarn", "npm"},
+	}
+	for _, c := range cases {
+		t.Setenv(pmEnv, c.env)
+		m, err := installPM(c.flag)
+		if err != nil || m.Name() != c.want {
+			t.Errorf("installPM(%q) with %s=%q = %v, %v; want %s", c.flag, pmEnv, c.env, m, err, c.want)
+		}
+	}
+}
+
+func TestInstallPM_Invalid(t *testing.T) {
+	pathWith(t, "node", "npm")
+	t.Setenv(pmEnv, "")
+
+	if _, err := installPM("cargo"); err == nil || !strings.Contains(err.Error(), "unknown package manager") {
+		t.Errorf("installPM(cargo) error = %v", err)
+	}
+	if _, err := installPM("bun"); err == nil || !strings.Contains(err.Error(), "not on PATH") {
+		t.Errorf("installPM(bun) error = %v", err)
+	}
+}
+
+func TestPlatformPM_ReusesRecordedManager(t *testing.T) {
+	pathWith(t, "node", "npm", "pnpm")
+	t.Setenv(pmEnv, "")
+
+	m, err := platformPM(&config.PlatformConfig{PM: "npm"}, "")
+	if err != nil || m.Name() != "npm" {
+		t.Errorf("platformPM(npm) = %v, %v; want npm", m, err)
+	}
+	if m, err := platformPM(&config.PlatformConfig{PM: "npm"}, "npm"); err != nil || m.Name() != "npm" {
+		t.Errorf("platformPM(npm, --pm npm) = %v, %v; want npm", m, err)
+	}
+	if m, err := platformPM(&config.PlatformConfig{}, "pnpm"); err != nil || m.Name() != "pnpm" {
+		t.Errorf("platformPM(unrecorded, --pm pnpm) = %v, %v; want pnpm", m, err)
+	}
+	t.Setenv(pmEnv, "pnpm")
+	if m, err := platformPM(&config.PlatformConfig{}, ""); err != nil || m.Name() != "pnpm" {
+		t.Errorf("platformPM(unrecorded) = %v, %v; want %s", m, err, pmEnv)
+	}
+	if _, err := platformPM(&config.PlatformConfig{PM: "yarn"}, "yarn"); err == nil || !strings.Contains(err.Error(), "no longer available") {
+		t.Errorf("platformPM(yarn missing) error = %v", err)
+	}
+}
+
+// TestPlatformPM_RefusesOtherManager verifies that --pm or KB_PM naming a
+// different manager than the recorded one is refused, not ignored.
+func TestPlatformPM_RefusesOtherManager(t *testing.T) {
+	pathWith(t, "node", "npm", "pnpm")
+
+	t.Setenv(pmEnv, "pnpm")
+	if _, err := platformPM(&config.PlatformConfig{PM: "npm"}, ""); err == nil || !strings.Contains(err.Error(), pmEnv+" pnpm") {
+		t.Errorf("platformPM(npm) with %s=pnpm error = %v, want a conflict", pmEnv, err)
+	}
+	t.Setenv(pmEnv, "")
+	if _, err := platformPM(&config.PlatformConfig{PM: "npm"}, "pnpm"); err == nil || !strings.Contains(err.Error(), "--pm pnpm") {
+		t.Errorf("platformPM(npm, --pm pnpm) error = %v, want a conflict", err)
+	}
+}
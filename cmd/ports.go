@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/kb-labs/create/internal/manifest"
+)
+
+// parsePortFlags turns --port values ("rest=5051", "rest.admin=5052") into
+// overrides keyed by manifest.PortKey and checks them against m.
+func parsePortFlags(values []string, m *manifest.Manifest) (map[string]int, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	ports := make(map[string]int, len(values))
+	for _, v := range values {
+		key, num, ok := strings.Cut(v, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("--port %q: want <service>=<port>", v)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(num))
+		if err != nil {
+			return nil, fmt.Errorf("--port %q: %q is not a number", v, num)
+		}
+		ports[strings.TrimSpace(key)] = n
+	}
+	if err := m.CheckPortOverrides(ports); err != nil {
+		return nil, fmt.Errorf("--port: %w", err)
+	}
+	return ports, nil
+}
+
+// portInUse reports whether something is already listening on localhost:port.
+func portInUse(port int) bool {
+	ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		return true
+	}
+	_ = ln.Close()
+	return false
+}
+
+// busyPorts returns the entries of ports that are already bound.
+func busyPorts(ports []manifest.ServicePort) []manifest.ServicePort {
+	var busy []manifest.ServicePort
+	for _, p := range ports {
+		if portInUse(p.Port) {
+			busy = append(busy, p)
+		}
+	}
+	return busy
+}
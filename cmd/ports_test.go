@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"net"
+	"testing"
+
+	"github.com/kb-labs/create/internal/manifest"
+)
+
+func portsManifest() *manifest.Manifest {
+	return &manifest.Manifest{Services: []manifest.Component{
+		{ID: "rest", Ports: []manifest.Port{{Port: 5050}}},
+		{ID: "studio", Ports: []manifest.Port{{Port: 3000}, {Name: "hmr", Port: 3001}}},
+	}}
+}
+
+func TestParsePortFlags(t *testing.T) {
+	got, err := parsePortFlags([]string{"rest=5051", "studio.hmr = 4001"}, portsManifest())
+	if err != nil {
+		t.Fatalf("parsePortFlags() error = %v", err)
+	}
+	if got["rest"] != 5051 || got["studio.hmr"] != 4001 || len(got) != 2 {
+		t.Errorf("parsePortFlags() = %v", got)
+	}
+}
+
+func TestParsePortFlags_Invalid(t *testing.T) {
+	for _, v := range []string{"rest", "=5051", "rest=abc", "mind=5051", "rest=0"} {
+		if _, err := parsePortFlags([]string{v}, portsManifest()); err == nil {
+			t.Errorf("parsePortFlags(%q) should fail", v)
+		}
+	}
+}
+
+func TestPortInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on localhost: %v", err)
+	}
+	defer func() { _ = ln.Close() }()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	if !portInUse(port) {
+		t.Errorf("portInUse(%d) = false while listening", port)
+	}
+	busy := busyPorts([]manifest.ServicePort{{Key: "rest", Service: "rest", Port: port}})
+	if len(busy) != 1 {
+		t.Errorf("busyPorts() = %v, want the bound port", busy)
+	}
+}
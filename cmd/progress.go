@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kb-labs/create/internal/pm"
+)
+
+// slowAfter is how long a package may stay unfinished before the spinner
+// names it as the one holding up the install.
+const slowAfter = 3 * time.Second
+
+// packageProgress counts per-package events from the package manager so the
+// spinner can show "12/37 packages" and which package is slow. Packages are
+// keyed by name: npm reports metadata requests without a version.
+type packageProgress struct {
+	now     func() time.Time
+	pending map[string]time.Time // first seen, until done
+	done    map[string]bool
+}
+
+func newPackageProgress(now func() time.Time) *packageProgress {
+	return &packageProgress{
+		now:     now,
+		pending: map[string]time.Time{},
+		done:    map[string]bool{},
+	}
+}
+
+// record notes a package event.
+func (p *packageProgress) record(ev pm.Progress) {
+	name := packageName(ev.Package)
+	if p.done[name] {
+		return
+	}
+	if ev.Done {
+		delete(p.pending, name)
+		p.done[name] = true
+		return
+	}
+	if _, ok := p.pending[name]; !ok {
+		p.pending[name] = p.now()
+	}
+}
+
+// empty reports whether no package events were recorded.
+func (p *packageProgress) empty() bool { return len(p.pending) == 0 && len(p.done) == 0 }
+
+// String returns "12/37 packages", followed by the longest-waiting package
+// once it has been pending for slowAfter.
+func (p *packageProgress) String() string {
+	s := fmt.Sprintf("%d/%d packages", len(p.done), len(p.done)+len(p.pending))
+
+	var slowest string
+	var since time.Time
+	for name, t := range p.pending {
+		if slowest == "" || t.Before(since) || (t.Equal(since) && name < slowest) {
+			slowest, since = name, t
+		}
+	}
+	if wait := p.now().Sub(since); slowest != "" && wait >= slowAfter {
+		s += fmt.Sprintf(" · waiting on %s (%s)", slowest, wait.Truncate(time.Second))
+	}
+	return s
+}
+
+// packageName strips the version from "name@version", keeping the "@" of a
+// scope.
+func packageName(pkg string) string {
+	if i := strings.LastIndex(pkg, "@"); i > 0 {
+		return pkg[:i]
+	}
+	return pkg
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kb-labs/create/internal/pm"
+)
+
+func TestPackageProgress(t *testing.T) {
+	now := time.Unix(0, 0)
+	p := newPackageProgress(func() time.Time { return now })
+	if !p.empty() {
+		t.Fatal("new packageProgress is not empty")
+	}
+
+	p.record(pm.Progress{Package: "left-pad", Phase: pm.PhaseResolved})
+	now = now.Add(time.Second)
+	p.record(pm.Progress{Package: "@kb-labs/sdk@1.2.3", Phase: pm.PhaseResolved})
+	p.record(pm.Progress{Package: "left-pad@1.3.0", Phase: pm.PhaseFetched, Done: true})
+	p.record(pm.Progress{Package: "left-pad@1.3.0", Phase: pm.PhaseLinked, Done: true})
+	if got, want := p.String(), "1/2 packages"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	now = now.Add(8500 * time.Millisecond)
+	if got, want := p.String(), "1/2 packages · waiting on @kb-labs/sdk (8s)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	p.record(pm.Progress{Package: "@kb-labs/sdk@1.2.3", Phase: pm.PhaseLinked, Done: true})
+	if got, want := p.String(), "2/2 packages"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	for in, want := range map[string]string{
+		"left-pad":           "left-pad",
+		"left-pad@1.3.0":     "left-pad",
+		"@kb-labs/sdk":       "@kb-labs/sdk",
+		"@kb-labs/sdk@1.2.3": "@kb-labs/sdk",
+	} {
+		if got := packageName(in); got != want {
+			t.Errorf("packageName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
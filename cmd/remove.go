@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kb-labs/create/internal/config"
+	"github.com/kb-labs/create/internal/installer"
+	"github.com/kb-labs/create/internal/logger"
+)
+
+var removeCmd = &cobra.Command{
+	Use:   "remove <id>",
+	Short: "Remove a service or plugin from an installed platform",
+	Long: `Uninstalls a service, plugin or third-party plugin by ID, drops it from
+kb.config.json so update does not bring it back, and sets it to disabled
+in the project's .kb/kb.config.jsonc. Components that other installed
+components require must be removed after them.
+
+Examples:
+  kb-create remove studio
+  kb-create remove @acme/kb-plugin-foo`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRemove,
+}
+
+func init() {
+	rootCmd.AddCommand(removeCmd)
+	addPMFlag(removeCmd)
+}
+
+func runRemove(cmd *cobra.Command, args []string) error {
+	out := newOutput()
+	id := args[0]
+
+	platformDir, err := resolvePlatformDir(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Read(platformDir)
+	if err != nil {
+		return err
+	}
+	packageManager, err := platformPM(cfg, flagPM)
+	if err != nil {
+		return err
+	}
+
+	log, err := logger.New(platformDir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = log.Close() }()
+
+	sp := newSpinner()
+	ins := &installer.Installer{
+		PM:  packageManager,
+		Log: log,
+		OnStep: func(step, total int, label string) {
+			sp.setLabel(fmt.Sprintf("[%d/%d] %s", step, total, label))
+		},
+		OnLine:     sp.setDetail,
+		OnProgress: sp.setProgress,
+	}
+
+	ctx, stop := interruptible(cmd.Context())
+	defer stop()
+
+	sp.start()
+	result, err := ins.Remove(ctx, platformDir, id)
+	sp.stop(err)
+	if err != nil && ctx.Err() != nil {
+		return interruptedError("remove", platformDir)
+	}
+	if err != nil {
+		return fmt.Errorf("remove failed: %w", err)
+	}
+
+	if result.Unscaffolded {
+		out.Warn(fmt.Sprintf("could not find an enabled entry for %s in %s/.kb/kb.config.jsonc; disable it by hand", id, cfg.CWD))
+	}
+	out.OK(fmt.Sprintf("Removed %s (%s)", id, result.Duration.Round(100*time.Millisecond)))
+	return nil
+}
@@ -8,6 +8,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// installerVersion is the running kb-create version, "dev" for local builds.
+var installerVersion = "dev"
+
 // SetVersionInfo is called from main.go with values injected at build time via -ldflags.
 // It must be called before Execute().
 func SetVersionInfo(version, commit, date string) {
@@ -15,6 +18,7 @@ func SetVersionInfo(version, commit, date string) {
 		"kb-create %s (commit %s, built %s)\n", version, commit, date,
 	))
 	rootCmd.Version = version
+	installerVersion = version
 }
 
 var rootCmd = &cobra.Command{
@@ -26,6 +30,9 @@ Examples:
   kb-create my-project           interactive wizard
   kb-create my-project --yes     silent install with defaults
   kb-create update               update an installed platform
+  kb-create add --pkg <spec>     add a third-party plugin
+  kb-create remove <id>          remove an installed component
+  kb-create bundle --out <file>  build an offline install bundle
   kb-create status               show installation status
   kb-create logs                 show install log
   kb-create doctor               verify local environment`,
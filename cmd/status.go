@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/kb-labs/create/internal/config"
+	"github.com/kb-labs/create/internal/manifest"
 )
 
 var statusCmd = &cobra.Command{
@@ -37,18 +39,31 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	out.KeyValue("PM", cfg.PM)
 	out.KeyValue("Installed", cfg.InstalledAt.Format("2006-01-02 15:04"))
 	out.KeyValue("Manifest", cfg.Manifest.Version)
+	out.KeyValue("Source", cfg.ManifestSource.String())
+	for _, o := range cfg.ManifestOverlays {
+		out.KeyValue("Overlay", o.String())
+	}
+	if cfg.Channel != "" {
+		out.KeyValue("Channel", cfg.Channel)
+	}
+	if src := cfg.ManifestSource; src.Kind == manifest.SourceRemote || src.Kind == manifest.SourceCache {
+		if entry, err := manifest.CacheInfo(manifest.DefaultCacheDir(), src.Location); err == nil {
+			out.KeyValue("Cached", fmt.Sprintf("fetched %s ago, revalidated %s ago",
+				humanAge(time.Since(entry.FetchedAt)), humanAge(time.Since(entry.ValidatedAt))))
+		}
+	}
 
 	// core
 	out.Section("Core packages")
 	for _, p := range cfg.Manifest.Core {
-		out.Bullet(p.Name, "")
+		out.Bullet(p.Name, cfg.Resolved[p.Name])
 	}
 
 	// services
 	if len(cfg.Manifest.Services) > 0 {
 		out.Section("Services")
 		for _, s := range cfg.Manifest.Services {
-			out.Bullet(s.ID, s.Description)
+			out.Bullet(s.ID, manifestDetails(cfg, s))
 		}
 	}
 
@@ -56,10 +71,50 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	if len(cfg.Manifest.Plugins) > 0 {
 		out.Section("Plugins")
 		for _, p := range cfg.Manifest.Plugins {
-			out.Bullet(p.ID, p.Description)
+			out.Bullet(p.ID, manifestDetails(cfg, p))
+		}
+	}
+
+	// third-party plugins added with --plugin or `kb-create add`
+	if len(cfg.UserPlugins) > 0 {
+		out.Section("User plugins")
+		for _, p := range cfg.UserPlugins {
+			out.Bullet(p.ID, componentDetails(cfg, p))
 		}
 	}
 
 	fmt.Println()
 	return nil
 }
+
+// componentDetails returns the component description prefixed with the
+// resolved package version when one was recorded at install time.
+func componentDetails(cfg *config.PlatformConfig, c manifest.Component) string {
+	if v := cfg.Resolved[c.Pkg]; v != "" {
+		return v + "  " + c.Description
+	}
+	return c.Description
+}
+
+// manifestDetails is componentDetails for the manifest's services and
+// plugins, which are all listed, marking those that aren't installed.
+func manifestDetails(cfg *config.PlatformConfig, c manifest.Component) string {
+	if !cfg.IsInstalled(c) {
+		return "not installed  " + c.Description
+	}
+	return componentDetails(cfg, c)
+}
+
+// humanAge renders a duration as a short "3h", "2d" style age.
+func humanAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,7 +15,6 @@ import (
 	"github.com/kb-labs/create/internal/installer"
 	"github.com/kb-labs/create/internal/logger"
 	"github.com/kb-labs/create/internal/manifest"
-	"github.com/kb-labs/create/internal/pm"
 )
 
 var updateCmd = &cobra.Command{
@@ -26,6 +27,8 @@ shows what changed, and applies updates after confirmation.`,
 
 func init() {
 	rootCmd.AddCommand(updateCmd)
+	addPMFlag(updateCmd)
+	addManifestFlags(updateCmd)
 }
 
 func runUpdate(cmd *cobra.Command, args []string) error {
@@ -36,9 +39,29 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	m, err := manifest.LoadDefault()
+	cfg, err := config.Read(platformDir)
 	if err != nil {
-		return fmt.Errorf("load manifest: %w", err)
+		return err
+	}
+	packageManager, err := platformPM(cfg, flagPM)
+	if err != nil {
+		return err
+	}
+
+	// Without --manifest/KB_MANIFEST, stay on the source and release channel
+	// used at install time. The channel, switched or not, is looked up in
+	// the recorded base manifest's channel map.
+	channel := cfg.Channel
+	if channel == "" {
+		channel = manifest.DefaultChannel
+	}
+	if flagChannel != "" && flagChannel != channel {
+		out.Info(fmt.Sprintf("Switching channel: %s → %s", channel, flagChannel))
+		channel = flagChannel
+	}
+	m, err := loadManifest(flagManifest, cfg, channel, manifestOverlays(cfg.ManifestOverlays))
+	if err != nil {
+		return err
 	}
 
 	log, err := logger.New(platformDir)
@@ -48,15 +71,24 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 	defer func() { _ = log.Close() }()
 
 	ins := &installer.Installer{
-		PM:  pm.Detect(),
+		PM:  packageManager,
 		Log: log,
 	}
 
 	out.Info("Checking for updates...")
-	diff, err := ins.Diff(platformDir, m)
+	checkCtx, stopCheck := interruptible(cmd.Context())
+	diff, err := ins.Diff(checkCtx, platformDir, m)
+	interrupted := checkCtx.Err() != nil
+	stopCheck()
+	if interrupted {
+		return errors.New("update check interrupted; nothing was changed")
+	}
 	if err != nil {
 		return err
 	}
+	for _, c := range diff.Deprecated {
+		out.Warn(fmt.Sprintf("%s is deprecated: %s", c.ID, c.Deprecated))
+	}
 
 	if !diff.HasChanges() {
 		out.OK("Already up to date")
@@ -70,11 +102,29 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	result, err := ins.Update(platformDir, m)
+	sp := newSpinner()
+	ins.OnStep = func(step, total int, label string) {
+		sp.setLabel(fmt.Sprintf("[%d/%d] %s", step, total, label))
+	}
+	ins.OnLine = sp.setDetail
+	ins.OnProgress = sp.setProgress
+
+	ctx, stop := interruptible(cmd.Context())
+	defer stop()
+
+	sp.start()
+	result, err := ins.Update(ctx, platformDir, m, diff)
+	sp.stop(err)
+	if err != nil && ctx.Err() != nil {
+		return interruptedError("update", platformDir)
+	}
 	if err != nil {
 		return fmt.Errorf("update failed: %w", err)
 	}
 
+	for _, id := range result.Unscaffolded {
+		out.Warn(fmt.Sprintf("%s/.kb/kb.config.jsonc already has an entry for the replacement of %s; merge its settings by hand", cfg.CWD, id))
+	}
 	out.OK(fmt.Sprintf("Update complete (%s)", result.Duration.Round(100*time.Millisecond)))
 	return nil
 }
@@ -90,8 +140,17 @@ func printDiff(out output, d *installer.UpdateDiff) {
 	}
 	if len(d.Updated) > 0 {
 		out.Info("Update:")
-		for _, p := range d.Updated {
-			fmt.Printf("  %s %s\n", out.bullet.Render("↑"), out.dim.Render(p))
+		for _, c := range d.Updated {
+			fmt.Printf("  %s %s %s\n", out.bullet.Render("↑"), c.Name, out.dim.Render(versionChange(c)))
+		}
+		if slices.ContainsFunc(d.Updated, func(c installer.VersionChange) bool { return c.To == "" }) {
+			out.Warn("Could not check for newer versions; packages marked ? are updated within their range (see kb-create logs)")
+		}
+	}
+	if len(d.Migrated) > 0 {
+		out.Info("Replace:")
+		for _, mg := range d.Migrated {
+			fmt.Printf("  %s %s → %s  %s\n", out.bullet.Render("»"), mg.From.ID, mg.To.ID, out.dim.Render(mg.From.Deprecated))
 		}
 	}
 	if len(d.Removed) > 0 {
@@ -103,6 +162,19 @@ func printDiff(out output, d *installer.UpdateDiff) {
 	fmt.Println()
 }
 
+// versionChange renders c's versions as "1.2.0 → 1.3.1", with "?" for a
+// version that could not be determined.
+func versionChange(c installer.VersionChange) string {
+	from, to := c.From, c.To
+	if from == "" {
+		from = "?"
+	}
+	if to == "" {
+		to = "?"
+	}
+	return from + " → " + to
+}
+
 func confirm(prompt string) bool {
 	fmt.Print(prompt)
 	r := bufio.NewReader(os.Stdin)
@@ -0,0 +1,303 @@
+// Package bundle reads and writes offline install bundles. A bundle is a
+// gzipped tarball holding everything needed to install the platform on a
+// machine without network access: a snapshot of the manifest, the
+// selection it was built for, the npm lockfile and an npm cache with every
+// package tarball the lockfile references.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/kb-labs/create/internal/manifest"
+)
+
+// FormatVersion is the bundle layout this kb-create writes and reads.
+const FormatVersion = 1
+
+// Entries of a bundle.
+const (
+	MetaFile     = "bundle.json"
+	ManifestFile = "manifest.json"
+	CacheDir     = "npm-cache" // npm --cache directory
+)
+
+// lockFiles are copied from the staging platform dir into the bundle and
+// back into the platform dir on install.
+var lockFiles = []string{"package.json", "package-lock.json"}
+
+// Meta describes what a bundle contains.
+type Meta struct {
+	FormatVersion int       `json:"formatVersion"`
+	CreatedAt     time.Time `json:"createdAt"`
+	CreatedBy     string    `json:"createdBy"` // kb-create version
+	// OS and Arch are the GOOS/GOARCH the bundle was built on. Packages
+	// with platform-specific binaries only work there.
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+
+	Services    []string             `json:"services"`
+	Plugins     []string             `json:"plugins"`
+	UserPlugins []manifest.Component `json:"userPlugins,omitempty"`
+	// Packages are the exact "name@version" specs of the top-level packages.
+	Packages []string `json:"packages"`
+}
+
+// NewMeta returns Meta for a bundle built now on this machine.
+func NewMeta(createdBy string) Meta {
+	return Meta{
+		FormatVersion: FormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		CreatedBy:     createdBy,
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+	}
+}
+
+// Bundle is an extracted bundle.
+type Bundle struct {
+	Dir      string // extraction directory
+	Meta     Meta
+	Manifest *manifest.Manifest
+}
+
+// CacheDir returns the npm cache directory to install from.
+func (b *Bundle) CacheDir() string { return filepath.Join(b.Dir, CacheDir) }
+
+// CheckHost returns an error if the bundle was built for another OS or
+// architecture than this machine's.
+func (b *Bundle) CheckHost() error {
+	if b.Meta.OS != runtime.GOOS || b.Meta.Arch != runtime.GOARCH {
+		return fmt.Errorf("bundle was built on %s/%s, this machine is %s/%s; build it on a matching machine",
+			b.Meta.OS, b.Meta.Arch, runtime.GOOS, runtime.GOARCH)
+	}
+	return nil
+}
+
+// CopyLockfile copies the bundle's package.json and package-lock.json into
+// dir so that npm installs exactly the bundled versions.
+func (b *Bundle) CopyLockfile(dir string) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	for _, name := range lockFiles {
+		// #nosec G304 -- name is one of the fixed lockFiles inside the extraction dir.
+		data, err := os.ReadFile(filepath.Join(b.Dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write packs m, meta and the lockfile and npm cache found in stagingDir
+// into a gzipped tarball at out.
+func Write(out, stagingDir string, m *manifest.Manifest, meta Meta) (err error) {
+	mdata, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal manifest: %w", err)
+	}
+	meta.FormatVersion = FormatVersion
+	metaData, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal bundle metadata: %w", err)
+	}
+
+	// #nosec G304 -- out is the bundle path the user asked for.
+	f, err := os.Create(out)
+	if err != nil {
+		return fmt.Errorf("create bundle: %w", err)
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			_ = os.Remove(out)
+		}
+	}()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	if err := writeEntry(tw, MetaFile, metaData); err != nil {
+		return err
+	}
+	if err := writeEntry(tw, ManifestFile, mdata); err != nil {
+		return err
+	}
+	for _, name := range lockFiles {
+		// #nosec G304 -- name is one of the fixed lockFiles inside stagingDir.
+		data, err := os.ReadFile(filepath.Join(stagingDir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := writeEntry(tw, name, data); err != nil {
+			return err
+		}
+	}
+	if err := writeTree(tw, stagingDir, CacheDir); err != nil {
+		return fmt.Errorf("pack npm cache: %w", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), ModTime: time.Now()}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// writeTree adds the regular files under root/rel to tw.
+func writeTree(tw *tar.Writer, root, rel string) error {
+	err := filepath.WalkDir(filepath.Join(root, rel), func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		name, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hdr := &tar.Header{Name: filepath.ToSlash(name), Mode: 0o600, Size: info.Size(), ModTime: info.ModTime()}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		// #nosec G304 -- p is a file inside the staging npm cache.
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Open extracts the bundle at file into dst and reads its metadata and
+// manifest. The manifest's Source records the bundle. Bundles needing a
+// newer kb-create than installerVersion are refused.
+func Open(file, dst, installerVersion string) (*Bundle, error) {
+	if err := extract(file, dst); err != nil {
+		return nil, fmt.Errorf("extract bundle %s: %w", file, err)
+	}
+
+	// #nosec G304 -- dst is the extraction dir created by the caller.
+	metaData, err := os.ReadFile(filepath.Join(dst, MetaFile))
+	if err != nil {
+		return nil, fmt.Errorf("bundle %s: %w", file, err)
+	}
+	var meta Meta
+	if err := json.Unmarshal(metaData, &meta); err != nil {
+		return nil, fmt.Errorf("bundle %s: parse %s: %w", file, MetaFile, err)
+	}
+	if meta.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("bundle %s has format version %d, this kb-create reads %d", file, meta.FormatVersion, FormatVersion)
+	}
+
+	// #nosec G304 -- dst is the extraction dir created by the caller.
+	mdata, err := os.ReadFile(filepath.Join(dst, ManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("bundle %s: %w", file, err)
+	}
+	var head struct {
+		MinInstallerVersion string `json:"minInstallerVersion"`
+	}
+	_ = json.Unmarshal(mdata, &head)
+	if err := manifest.CheckInstallerVersion(head.MinInstallerVersion, installerVersion); err != nil {
+		return nil, err
+	}
+	m, err := manifest.Parse(mdata)
+	if err != nil {
+		return nil, fmt.Errorf("bundle %s: %w", file, err)
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		abs = file
+	}
+	m.Source = manifest.Source{Kind: manifest.SourceBundle, Location: abs}
+
+	return &Bundle{Dir: dst, Meta: meta, Manifest: m}, nil
+}
+
+// extract unpacks the regular files of a gzipped tarball into dst,
+// refusing entries that would land outside it.
+func extract(file, dst string) error {
+	// #nosec G304 -- file is the bundle path the user passed.
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(hdr.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("entry %q is outside the bundle", hdr.Name)
+		}
+		target := filepath.Join(dst, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+			return err
+		}
+		// #nosec G304 -- target is checked to stay inside dst.
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		if err != nil {
+			return err
+		}
+		// #nosec G110 -- bundles are produced by kb-create bundle and opened explicitly.
+		_, err = io.Copy(out, tr)
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
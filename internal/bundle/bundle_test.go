@@ -0,0 +1,133 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kb-labs/create/internal/manifest"
+)
+
+// stage returns a scratch platform dir with a lockfile and an npm cache.
+func stage(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"package.json":                     `{"dependencies":{"@kb-labs/sdk":"^1.2.3"}}`,
+		"package-lock.json":                `{"lockfileVersion":3}`,
+		".npmrc":                           "//npm.corp.example/:_authToken=secret\n",
+		"npm-cache/_cacache/content-v2/ab": "tarball",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// TestWriteOpenRoundTrip verifies that a written bundle opens with its
+// metadata, manifest, lockfile and cache, and without the staging .npmrc.
+func TestWriteOpenRoundTrip(t *testing.T) {
+	m, err := manifest.LoadDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := NewMeta("1.4.0")
+	meta.Services = []string{"rest"}
+	meta.Packages = []string{"@kb-labs/sdk@1.2.3"}
+
+	file := filepath.Join(t.TempDir(), "kb.tgz")
+	if err := Write(file, stage(t), m, meta); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	b, err := Open(file, t.TempDir(), "1.4.0")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if !slices.Equal(b.Meta.Packages, meta.Packages) || !slices.Equal(b.Meta.Services, meta.Services) {
+		t.Errorf("Meta = %+v, want %+v", b.Meta, meta)
+	}
+	if b.Manifest.Version != m.Version || b.Manifest.Source.Kind != manifest.SourceBundle || b.Manifest.Source.Location != file {
+		t.Errorf("Manifest version %q, source %v; want %q from the bundle", b.Manifest.Version, b.Manifest.Source, m.Version)
+	}
+	if err := b.CheckHost(); err != nil {
+		t.Errorf("CheckHost() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(b.CacheDir(), "_cacache", "content-v2", "ab")); err != nil {
+		t.Errorf("npm cache not extracted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(b.Dir, ".npmrc")); !os.IsNotExist(err) {
+		t.Error(".npmrc with auth tokens was bundled")
+	}
+
+	platform := t.TempDir()
+	if err := b.CopyLockfile(platform); err != nil {
+		t.Fatalf("CopyLockfile() error = %v", err)
+	}
+	// #nosec G304 -- reads a file written under the test's temp dir.
+	if lock, err := os.ReadFile(filepath.Join(platform, "package-lock.json")); err != nil || string(lock) != `{"lockfileVersion":3}` {
+		t.Errorf("package-lock.json = %q, %v", lock, err)
+	}
+}
+
+// TestCheckHostMismatch verifies that a bundle built elsewhere is refused.
+func TestCheckHostMismatch(t *testing.T) {
+	b := &Bundle{Meta: Meta{OS: "plan9", Arch: runtime.GOARCH}}
+	if err := b.CheckHost(); err == nil || !strings.Contains(err.Error(), "plan9") {
+		t.Errorf("CheckHost() = %v, want an OS mismatch", err)
+	}
+}
+
+// TestOpenRejectsEscapingEntries verifies that entries outside the
+// extraction dir are refused.
+func TestOpenRejectsEscapingEntries(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "evil.tgz")
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	if err := writeEntry(tw, "../escaped", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	_ = tw.Close()
+	_ = gz.Close()
+	_ = f.Close()
+
+	dst := filepath.Join(t.TempDir(), "out")
+	if _, err := Open(file, dst, "dev"); err == nil || !strings.Contains(err.Error(), "outside the bundle") {
+		t.Errorf("Open() error = %v, want entry outside the bundle", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dst), "escaped")); !os.IsNotExist(err) {
+		t.Error("escaping entry was written")
+	}
+}
+
+// TestOpenRefusesNewerManifest verifies that a bundle whose manifest needs
+// a newer kb-create is refused.
+func TestOpenRefusesNewerManifest(t *testing.T) {
+	m, err := manifest.LoadDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.MinInstallerVersion = "99.0.0"
+	file := filepath.Join(t.TempDir(), "kb.tgz")
+	if err := Write(file, stage(t), m, NewMeta("dev")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(file, t.TempDir(), "1.0.0"); err == nil {
+		t.Error("Open() accepted a manifest requiring kb-create 99.0.0")
+	}
+}
@@ -4,10 +4,12 @@
 package config
 
 import (
+	"crypto/ed25519"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/kb-labs/create/internal/manifest"
@@ -35,8 +37,54 @@ type PlatformConfig struct {
 	CWD         string            `json:"cwd"`
 	PM          string            `json:"pm"`
 	Manifest    manifest.Manifest `json:"manifest"`
-	Telemetry   TelemetryConfig   `json:"telemetry"`
-	Version     int               `json:"version"`
+	// ManifestSource records where Manifest was loaded from (remote URL,
+	// local override file or the embedded copy).
+	ManifestSource manifest.Source `json:"manifestSource"`
+	// ManifestBase records where the manifest whose channel map pointed at
+	// ManifestSource came from, when the platform follows another channel
+	// than its base manifest's. Later loads start from it.
+	ManifestBase manifest.Source `json:"manifestBase,omitzero"`
+	// ManifestOverlays records, in merge order, where each overlay layered
+	// on top of the base manifest came from.
+	ManifestOverlays []manifest.Source `json:"manifestOverlays,omitempty"`
+	// ManifestKeys are the user-supplied public keys (--manifest-key or
+	// KB_MANIFEST_KEYS) the manifest was verified with. Update and doctor
+	// keep trusting them. --insecure-manifest is never recorded.
+	ManifestKeys []ed25519.PublicKey `json:"manifestKeys,omitempty"`
+	// Channel is the release channel (stable, beta, nightly) the platform
+	// follows; update stays on it unless switched explicitly.
+	Channel string `json:"channel,omitempty"`
+	// Components lists the IDs of the installed manifest services and
+	// plugins, so components left out at install time or removed later stay
+	// out across updates. Configs written before it was recorded fall back
+	// to Resolved; see IsInstalled.
+	Components []string `json:"components,omitzero"`
+	// Resolved maps each installed package name to the exact version the
+	// package manager resolved for it (e.g. "@kb-labs/mind": "1.3.1").
+	Resolved map[string]string `json:"resolved,omitempty"`
+	// UserPlugins are third-party plugins the user added by npm spec
+	// (--plugin or `kb-create add`), kept across updates.
+	UserPlugins []manifest.Component `json:"userPlugins,omitempty"`
+	// Ports holds the service port overrides chosen at install time, keyed
+	// by manifest.PortKey. Services not listed use the manifest default.
+	Ports     map[string]int  `json:"ports,omitempty"`
+	Telemetry TelemetryConfig `json:"telemetry"`
+	Version   int             `json:"version"`
+}
+
+// IsInstalled reports whether the manifest service or plugin c is installed
+// according to Components or, in configs without it, the versions in
+// Resolved or, without any, the installed manifest snapshot.
+func (cfg *PlatformConfig) IsInstalled(c manifest.Component) bool {
+	if cfg.Components != nil {
+		return slices.Contains(cfg.Components, c.ID)
+	}
+	if len(cfg.Resolved) > 0 {
+		_, ok := cfg.Resolved[c.Pkg]
+		return ok
+	}
+	old, ok := cfg.Manifest.Component(c.ID)
+	return ok && old.Pkg == c.Pkg
 }
 
 // ConfigPath returns the path to the config file for the given platform directory.
@@ -90,12 +138,17 @@ func NewConfig(platformDir, cwd, pmName string, m *manifest.Manifest, t Telemetr
 	abs, _ := filepath.Abs(platformDir)
 	absCWD, _ := filepath.Abs(cwd)
 	return &PlatformConfig{
-		Version:     configVersion,
-		Platform:    abs,
-		CWD:         absCWD,
-		PM:          pmName,
-		InstalledAt: time.Now().UTC(),
-		Manifest:    *m,
-		Telemetry:   t,
+		Version:          configVersion,
+		Platform:         abs,
+		CWD:              absCWD,
+		PM:               pmName,
+		InstalledAt:      time.Now().UTC(),
+		Manifest:         *m,
+		ManifestSource:   m.Source,
+		ManifestBase:     m.BaseSource,
+		ManifestOverlays: m.Overlays,
+		ManifestKeys:     m.TrustedKeys,
+		Channel:          m.ChannelName(),
+		Telemetry:        t,
 	}
 }
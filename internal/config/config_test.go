@@ -39,6 +39,9 @@ func TestNewConfig(t *testing.T) {
 	if cfg.Manifest.Version != "1.0.0" {
 		t.Errorf("Manifest.Version = %q, want %q", cfg.Manifest.Version, "1.0.0")
 	}
+	if cfg.Channel != "stable" {
+		t.Errorf("Channel = %q, want %q", cfg.Channel, "stable")
+	}
 }
 
 // TestWriteThenRead verifies round-trip write → read produces identical config.
@@ -48,6 +51,7 @@ func TestWriteThenRead(t *testing.T) {
 	want := NewConfig(dir, "/some/project", "npm", &m, TelemetryConfig{Enabled: true, DeviceID: "abc123"})
 	// Fix timestamp for deterministic comparison.
 	want.InstalledAt = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	want.Resolved = map[string]string{"@kb-labs/cli-bin": "1.2.3"}
 
 	if err := Write(dir, want); err != nil {
 		t.Fatalf("Write() error = %v", err)
@@ -73,6 +77,9 @@ func TestWriteThenRead(t *testing.T) {
 	if len(got.Manifest.Core) != len(want.Manifest.Core) {
 		t.Errorf("Core len: got %d, want %d", len(got.Manifest.Core), len(want.Manifest.Core))
 	}
+	if got.Resolved["@kb-labs/cli-bin"] != "1.2.3" {
+		t.Errorf("Resolved: got %v, want %v", got.Resolved, want.Resolved)
+	}
 }
 
 // TestReadMissing verifies that reading a non-existent config returns an error.
@@ -110,3 +117,37 @@ func TestWriteCreatesDirectory(t *testing.T) {
 		t.Errorf("config file not created: %v", err)
 	}
 }
+
+// TestIsInstalled verifies that recorded components win over recorded
+// versions, which win over the manifest snapshot, and that an empty
+// component list survives a write and read.
+func TestIsInstalled(t *testing.T) {
+	m := sampleManifest()
+	rest, mind := m.Services[0], m.Plugins[0]
+	cfg := NewConfig(t.TempDir(), t.TempDir(), "npm", &m, TelemetryConfig{})
+
+	if !cfg.IsInstalled(rest) || !cfg.IsInstalled(mind) {
+		t.Error("IsInstalled() = false without records, want the manifest snapshot")
+	}
+	cfg.Resolved = map[string]string{"@kb-labs/rest-api": "1.0.0"}
+	if !cfg.IsInstalled(rest) || cfg.IsInstalled(mind) {
+		t.Error("IsInstalled() ignores Resolved")
+	}
+	cfg.Components = []string{"mind"}
+	if cfg.IsInstalled(rest) || !cfg.IsInstalled(mind) {
+		t.Error("IsInstalled() ignores Components")
+	}
+
+	dir := t.TempDir()
+	cfg.Components = []string{}
+	if err := Write(dir, cfg); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Components == nil || got.IsInstalled(rest) {
+		t.Errorf("Components after round trip = %#v, want an empty list", got.Components)
+	}
+}
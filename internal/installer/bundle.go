@@ -0,0 +1,60 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kb-labs/create/internal/bundle"
+	"github.com/kb-labs/create/internal/manifest"
+)
+
+// BundleResult is returned after a successful Bundle.
+type BundleResult struct {
+	Path     string
+	Packages []string // exact top-level "name@version" specs
+	Duration time.Duration
+}
+
+// Bundle builds an offline install bundle at out for the components in sel.
+// It installs them into sel.PlatformDir, a scratch directory, with ins.PM,
+// which must be an npm manager whose Cache is the bundle.CacheDir there, so
+// that the cache ends up holding every package tarball. The cache is then
+// packed with the lockfile, a snapshot of m and meta completed with the
+// selection. Host constraints are not checked: the bundle is installed
+// on another machine, which checks them then.
+func (ins *Installer) Bundle(ctx context.Context, sel *Selection, m *manifest.Manifest, out string, meta bundle.Meta) (*BundleResult, error) {
+	start := time.Now()
+
+	if err := ins.resolveSelection(sel, m); err != nil {
+		return nil, err
+	}
+	if err := ins.configureRegistry(sel.PlatformDir, m); err != nil {
+		return nil, err
+	}
+
+	allPkgs := ins.installSpecs(sel, m)
+	ins.step(1, 2, fmt.Sprintf("Downloading %d packages via %s", len(allPkgs), ins.PM.Name()))
+	if err := ins.installGroup(ctx, sel.PlatformDir, allPkgs); err != nil {
+		return nil, fmt.Errorf("install: %w", err)
+	}
+
+	ins.step(2, 2, "Writing bundle")
+	resolved := ins.resolvedVersions(ctx, sel.PlatformDir)
+	meta.Services = sel.Services
+	meta.Plugins = sel.Plugins
+	meta.UserPlugins = sel.UserPlugins
+	meta.Packages = make([]string, 0, len(allPkgs))
+	for _, spec := range allPkgs {
+		// Pin what was resolved so the offline install can't pick anything else.
+		if name, _ := manifest.ParseSpec(spec); resolved[name] != "" {
+			spec = name + "@" + resolved[name]
+		}
+		meta.Packages = append(meta.Packages, spec)
+	}
+	if err := bundle.Write(out, sel.PlatformDir, m, meta); err != nil {
+		return nil, err
+	}
+
+	return &BundleResult{Path: out, Packages: meta.Packages, Duration: time.Since(start)}, nil
+}
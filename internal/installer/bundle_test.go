@@ -0,0 +1,74 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kb-labs/create/internal/bundle"
+	"github.com/kb-labs/create/internal/config"
+	"github.com/kb-labs/create/internal/manifest"
+	"github.com/kb-labs/create/internal/pm"
+)
+
+// TestBundleThenInstallOffline verifies that Bundle pins the resolved
+// versions and that installing from the bundle uses them, copies the
+// lockfile and records the bundled manifest.
+func TestBundleThenInstallOffline(t *testing.T) {
+	staging := t.TempDir()
+	if err := os.WriteFile(filepath.Join(staging, "package-lock.json"), []byte(`{"lockfileVersion":3}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakePM{name: "npm", installed: []pm.InstalledPackage{
+		{Name: "@kb-labs/cli-bin", Version: "1.2.3"},
+		{Name: "@kb-labs/sdk", Version: "0.9.0"},
+		{Name: "@kb-labs/rest-api", Version: "2.0.1"},
+	}}
+	ins := &Installer{PM: fake, Log: discardLogger()}
+	m := sampleManifest()
+
+	file := filepath.Join(t.TempDir(), "kb.tgz")
+	res, err := ins.Bundle(context.Background(), &Selection{PlatformDir: staging, Services: []string{"rest"}}, &m, file, bundle.NewMeta("dev"))
+	if err != nil {
+		t.Fatalf("Bundle() error = %v", err)
+	}
+	want := []string{"@kb-labs/cli-bin@1.2.3", "@kb-labs/sdk@0.9.0", "@kb-labs/rest-api@2.0.1"}
+	if !slices.Equal(res.Packages, want) {
+		t.Errorf("Packages = %v, want %v", res.Packages, want)
+	}
+
+	b, err := bundle.Open(file, t.TempDir(), "dev")
+	if err != nil {
+		t.Fatalf("bundle.Open() error = %v", err)
+	}
+	platformDir := t.TempDir()
+	offline := &fakePM{name: "npm"}
+	ins = &Installer{PM: offline, Log: discardLogger()}
+	sel := &Selection{PlatformDir: platformDir, ProjectCWD: t.TempDir(), Services: b.Meta.Services, Plugins: b.Meta.Plugins, Bundle: b}
+	if _, err := ins.Install(context.Background(), sel, b.Manifest); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	var calls []string
+	for _, p := range want {
+		calls = append(calls, "install:"+p)
+	}
+	if !slices.Equal(offline.calls, calls) {
+		t.Errorf("PM calls = %v, want %v", offline.calls, calls)
+	}
+	if _, err := os.Stat(filepath.Join(platformDir, "package-lock.json")); err != nil {
+		t.Errorf("lockfile not copied: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(platformDir, ".npmrc")); !os.IsNotExist(err) {
+		t.Error("offline install wrote registry settings")
+	}
+	cfg, err := config.Read(platformDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ManifestSource.Kind != manifest.SourceBundle || cfg.Manifest.Version != m.Version {
+		t.Errorf("config manifest %q from %v, want the bundled one", cfg.Manifest.Version, cfg.ManifestSource)
+	}
+}
@@ -4,10 +4,13 @@
 package installer
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/kb-labs/create/internal/bundle"
 	"github.com/kb-labs/create/internal/config"
 	"github.com/kb-labs/create/internal/logger"
 	"github.com/kb-labs/create/internal/manifest"
@@ -19,9 +22,14 @@ import (
 type Selection struct {
 	PlatformDir string
 	ProjectCWD  string
-	Services    []string // component IDs
-	Plugins     []string // component IDs
+	Services    []string             // component IDs
+	Plugins     []string             // component IDs
+	Ports       map[string]int       // service port overrides keyed by manifest.PortKey
+	UserPlugins []manifest.Component // third-party plugins outside the manifest
 	Telemetry   config.TelemetryConfig
+	// Bundle, if set, is an extracted offline bundle to install from
+	// instead of the registry; see Installer.Bundle.
+	Bundle *bundle.Bundle
 }
 
 // Result is returned after a successful Install.
@@ -34,20 +42,47 @@ type Result struct {
 
 // UpdateDiff describes changes between the installed manifest and the current one.
 type UpdateDiff struct {
-	Updated []string // packages with version changes
-	Added   []string // new packages
-	Removed []string // removed packages
+	Updated []VersionChange // installed packages with a newer version
+	Added   []string        // new packages
+	Removed []string        // removed packages
+	// Migrated lists installed deprecated components and their replacements.
+	Migrated []Migration
+	// Deprecated lists installed deprecated components without a
+	// replacement. They are left installed.
+	Deprecated []manifest.Component
+}
+
+// VersionChange is an installed package Update moves to another version.
+// To is empty when the package manager could not be asked for newer
+// versions; From is empty when installed versions could not be listed.
+type VersionChange struct {
+	Name string
+	From string
+	To   string
+	// Spec is set when the manifest changed the package's version range. It
+	// is the install spec ("name@range") Update installs, and To is the new
+	// range ("latest" if there is none).
+	Spec string
+}
+
+// Migration replaces an installed deprecated component with its successor.
+type Migration struct {
+	From manifest.Component // deprecated component
+	To   manifest.Component // component named by From.ReplacedBy
 }
 
 // HasChanges returns true if there is anything to update.
 func (d *UpdateDiff) HasChanges() bool {
-	return len(d.Updated)+len(d.Added)+len(d.Removed) > 0
+	return len(d.Updated)+len(d.Added)+len(d.Removed)+len(d.Migrated) > 0
 }
 
 // UpdateResult is returned after a successful Update.
 type UpdateResult struct {
-	Diff     *UpdateDiff
-	Duration time.Duration
+	Diff *UpdateDiff
+	// Unscaffolded lists migrated components whose entry in the project's
+	// kb.config.jsonc was not renamed because the replacement already has one.
+	Unscaffolded []string
+	Duration     time.Duration
 }
 
 // Installer orchestrates platform installation and updates.
@@ -55,27 +90,60 @@ type Installer struct {
 	PM     pm.PackageManager
 	Log    *logger.Logger
 	OnStep func(step, total int, label string) // called at each named stage
-	OnLine func(line string)                   // called for each raw output line from pm
+	OnLine func(line string)                   // called for each pm output line, secrets redacted
+	// OnProgress, if set, is called for each per-package event (Package set)
+	// reported by package managers with machine-readable output.
+	OnProgress func(p pm.Progress)
+	// Host, if set, is checked against manifest and component constraints
+	// before anything is installed.
+	Host *manifest.Host
 }
 
 // Install installs the platform according to sel.
 // All selected packages are passed to the package manager in a single
 // invocation so it can resolve and deduplicate the dependency graph at once.
-func (ins *Installer) Install(sel *Selection, m *manifest.Manifest) (*Result, error) {
+func (ins *Installer) Install(ctx context.Context, sel *Selection, m *manifest.Manifest) (*Result, error) {
 	start := time.Now()
 
-	// Collect every package that needs to be installed in one shot.
-	allPkgs := m.CorePackageNames()
-	allPkgs = append(allPkgs, ins.selectedPkgs(m.Services, sel.Services)...)
-	allPkgs = append(allPkgs, ins.selectedPkgs(m.Plugins, sel.Plugins)...)
+	if err := ins.resolveSelection(sel, m); err != nil {
+		return nil, err
+	}
+	if err := ins.checkConstraints(sel, m); err != nil {
+		return nil, err
+	}
+	if err := m.CheckPortOverrides(sel.Ports); err != nil {
+		return nil, err
+	}
 
-	ins.step(1, 2, fmt.Sprintf("Installing %d packages via %s", len(allPkgs), ins.PM.Name()))
-	if err := ins.installGroup(sel.PlatformDir, allPkgs); err != nil {
+	allPkgs := ins.installSpecs(sel, m)
+	post := postInstallSteps(m, append(append([]string{}, sel.Services...), sel.Plugins...))
+	total := 2 + len(post)
+
+	if sel.Bundle != nil {
+		// Offline: npm installs the bundle's locked versions from its cache.
+		if err := sel.Bundle.CopyLockfile(sel.PlatformDir); err != nil {
+			return nil, fmt.Errorf("copy bundle lockfile: %w", err)
+		}
+		allPkgs = sel.Bundle.Meta.Packages
+	} else if err := ins.configureRegistry(sel.PlatformDir, m); err != nil {
+		return nil, err
+	}
+
+	ins.step(1, total, fmt.Sprintf("Installing %d packages via %s", len(allPkgs), ins.PM.Name()))
+	if err := ins.installGroup(ctx, sel.PlatformDir, allPkgs); err != nil {
 		return nil, fmt.Errorf("install: %w", err)
 	}
 
-	ins.step(2, 2, "Writing config")
+	if err := ins.runPostInstall(ctx, sel.PlatformDir, post, 2, total); err != nil {
+		return nil, err
+	}
+
+	ins.step(total, total, "Writing config")
 	cfg := config.NewConfig(sel.PlatformDir, sel.ProjectCWD, ins.PM.Name(), m, sel.Telemetry)
+	cfg.Resolved = ins.resolvedVersions(ctx, sel.PlatformDir)
+	cfg.Ports = sel.Ports
+	cfg.UserPlugins = sel.UserPlugins
+	cfg.Components = append(append([]string{}, sel.Services...), sel.Plugins...)
 	if err := config.Write(sel.PlatformDir, cfg); err != nil {
 		return nil, fmt.Errorf("config: %w", err)
 	}
@@ -84,8 +152,11 @@ func (ins *Installer) Install(sel *Selection, m *manifest.Manifest) (*Result, er
 	// documented starting point (JSONC with inline comments).
 	if err := scaffold.WriteProjectConfig(sel.ProjectCWD, scaffold.Options{
 		PlatformDir: sel.PlatformDir,
+		Manifest:    m,
 		Services:    sel.Services,
 		Plugins:     sel.Plugins,
+		Ports:       sel.Ports,
+		UserPlugins: sel.UserPlugins,
 	}); err != nil {
 		return nil, fmt.Errorf("scaffold project config: %w", err)
 	}
@@ -98,68 +169,348 @@ func (ins *Installer) Install(sel *Selection, m *manifest.Manifest) (*Result, er
 	}, nil
 }
 
-// Diff computes what would change if Update were applied now.
-func (ins *Installer) Diff(platformDir string, current *manifest.Manifest) (*UpdateDiff, error) {
+// Diff computes what would change if Update were applied now. Components
+// that installed ones newly require in current are added, and new conflicts
+// among them are an error. Installed packages whose version range differs
+// between the installed manifest and current move to the new range. For the others, version changes come from
+// asking the package manager which installed packages are outdated, which
+// needs the registry.
+func (ins *Installer) Diff(ctx context.Context, platformDir string, current *manifest.Manifest) (*UpdateDiff, error) {
 	cfg, err := config.Read(platformDir)
 	if err != nil {
 		return nil, err
 	}
 
-	installed := pkgSet(cfg.Manifest)
-	currentSet := pkgSet(*current)
+	installed := pkgRanges(cfg.Manifest)
+	currentRanges := pkgRanges(*current)
 
 	diff := &UpdateDiff{}
-	for pkg := range currentSet {
-		if _, ok := installed[pkg]; !ok {
+	kept := pkgNames(cfg.UserPlugins)
+	ranged := make(map[string]string)
+	for pkg, r := range currentRanges {
+		old, ok := installed[pkg]
+		if !ok {
 			diff.Added = append(diff.Added, pkg)
+			continue
+		}
+		kept = append(kept, pkg)
+		if old != r {
+			ranged[pkg] = r
+		}
+	}
+	diff.Updated = ins.versionChanges(ctx, platformDir, kept, ranged)
+	deprecated := make(map[string]bool)
+	for _, c := range append(append([]manifest.Component{}, current.Services...), current.Plugins...) {
+		if !c.IsDeprecated() || !cfg.IsInstalled(c) {
+			continue
+		}
+		deprecated[c.Pkg] = true
+		if to, ok := current.Replacement(c.ID); ok {
+			diff.Migrated = append(diff.Migrated, Migration{From: c, To: to})
 		} else {
-			// version comparison not available without npm query; mark as updated
-			diff.Updated = append(diff.Updated, pkg)
+			diff.Deprecated = append(diff.Deprecated, c)
 		}
 	}
 	for pkg := range installed {
-		if _, ok := currentSet[pkg]; !ok {
+		if _, ok := currentRanges[pkg]; !ok && !deprecated[pkg] {
 			diff.Removed = append(diff.Removed, pkg)
 		}
 	}
+
+	// The new manifest may add requirements or conflicts to what is
+	// installed: pull in newly required components and refuse conflicts.
+	ids := installedComponents(cfg, current, nil)
+	for _, mg := range diff.Migrated {
+		ids = append(ids, mg.To.ID)
+	}
+	resolved, err := current.Resolve(ids)
+	if err != nil {
+		return nil, fmt.Errorf("installed components: %w", err)
+	}
+	for _, id := range resolved {
+		c, _ := current.Component(id)
+		if !slices.Contains(ids, id) && !slices.Contains(diff.Added, c.Pkg) {
+			diff.Added = append(diff.Added, c.Pkg)
+		}
+	}
 	return diff, nil
 }
 
-// Update applies the diff: installs new packages, updates existing ones.
-func (ins *Installer) Update(platformDir string, current *manifest.Manifest) (*UpdateResult, error) {
+// Update applies diff, as computed by Diff for current: installs new
+// packages and packages whose version range changed, and updates the rest
+// within their ranges.
+func (ins *Installer) Update(ctx context.Context, platformDir string, current *manifest.Manifest, diff *UpdateDiff) (*UpdateResult, error) {
 	start := time.Now()
 
-	diff, err := ins.Diff(platformDir, current)
+	cfg, err := config.Read(platformDir)
 	if err != nil {
 		return nil, err
 	}
+	if err := ins.configureRegistry(platformDir, current); err != nil {
+		return nil, err
+	}
 
-	if len(diff.Added) > 0 {
-		ins.Log.Printf("Installing new packages: %s", strings.Join(diff.Added, " "))
-		if err := ins.installGroup(platformDir, diff.Added); err != nil {
+	// An update stays within the range saved in package.json, so packages
+	// with a new range are installed with it instead.
+	ranged := make(map[string]string)
+	for _, c := range diff.Updated {
+		if c.Spec != "" {
+			ranged[c.Name] = c.Spec
+		}
+	}
+	var allPkgs, rangedPkgs []string
+	add := func(name, spec string) {
+		if s, ok := ranged[name]; ok {
+			rangedPkgs = append(rangedPkgs, s)
+		} else {
+			allPkgs = append(allPkgs, spec)
+		}
+	}
+	for _, p := range current.Core {
+		add(p.Name, p.Spec())
+	}
+	for _, c := range append(current.Services, current.Plugins...) {
+		// Components that were never installed or were removed stay out.
+		if !c.IsDeprecated() && cfg.IsInstalled(c) {
+			add(c.Pkg, c.Spec())
+		}
+	}
+	allPkgs = append(allPkgs, specs(cfg.UserPlugins)...)
+
+	// Replacements are installed with the new packages, and the deprecated
+	// packages they replace are uninstalled once everything else is updated.
+	ranges := pkgRanges(*current)
+	newPkgs, added := make([]string, 0, len(diff.Added)), append([]string{}, diff.Added...)
+	for _, name := range diff.Added {
+		newPkgs = append(newPkgs, manifest.Package{Name: name, Version: ranges[name]}.Spec())
+	}
+	var oldPkgs []string
+	for _, mg := range diff.Migrated {
+		if !slices.Contains(added, mg.To.Pkg) {
+			newPkgs = append(newPkgs, mg.To.Spec())
+			added = append(added, mg.To.Pkg)
+		}
+		oldPkgs = append(oldPkgs, mg.From.Pkg)
+	}
+	components := installedComponents(cfg, current, added)
+	post := postInstallSteps(current, components)
+
+	n, total := 1, 1+len(post)
+	for _, group := range [][]string{newPkgs, rangedPkgs, allPkgs, oldPkgs} {
+		if len(group) > 0 {
+			total++
+		}
+	}
+	if len(newPkgs) > 0 {
+		ins.step(n, total, fmt.Sprintf("Installing %d new packages via %s", len(newPkgs), ins.PM.Name()))
+		if err := ins.installGroup(ctx, platformDir, newPkgs); err != nil {
 			return nil, fmt.Errorf("add new packages: %w", err)
 		}
+		n++
 	}
 
-	allPkgs := current.CorePackageNames()
-	for _, c := range append(current.Services, current.Plugins...) {
-		allPkgs = append(allPkgs, c.Pkg)
+	if len(rangedPkgs) > 0 {
+		ins.step(n, total, fmt.Sprintf("Installing %d packages with new version ranges via %s", len(rangedPkgs), ins.PM.Name()))
+		if err := ins.installGroup(ctx, platformDir, rangedPkgs); err != nil {
+			return nil, fmt.Errorf("install new version ranges: %w", err)
+		}
+		n++
 	}
-	if err := ins.updateGroup(platformDir, allPkgs); err != nil {
-		return nil, fmt.Errorf("update packages: %w", err)
+
+	if len(allPkgs) > 0 {
+		ins.step(n, total, fmt.Sprintf("Updating %d packages via %s", len(allPkgs), ins.PM.Name()))
+		if err := ins.updateGroup(ctx, platformDir, allPkgs); err != nil {
+			return nil, fmt.Errorf("update packages: %w", err)
+		}
+		n++
+	}
+
+	if len(oldPkgs) > 0 {
+		ins.step(n, total, fmt.Sprintf("Removing %d deprecated packages via %s", len(oldPkgs), ins.PM.Name()))
+		if err := ins.uninstallGroup(ctx, platformDir, oldPkgs); err != nil {
+			return nil, fmt.Errorf("remove deprecated packages: %w", err)
+		}
+		n++
+	}
+
+	if err := ins.runPostInstall(ctx, platformDir, post, n, total); err != nil {
+		return nil, err
 	}
 
 	// Refresh config snapshot.
+	ins.step(total, total, "Writing config")
+	res := &UpdateResult{Diff: diff}
+	for _, mg := range diff.Migrated {
+		ins.Log.Printf("  %s replaced by %s", mg.From.ID, mg.To.ID)
+		ok, err := scaffold.ReplaceComponent(cfg.CWD, mg.From, mg.To)
+		if err != nil {
+			return nil, fmt.Errorf("scaffold project config: %w", err)
+		}
+		if !ok {
+			res.Unscaffolded = append(res.Unscaffolded, mg.From.ID)
+		}
+		renamePortKeys(cfg.Ports, mg.From.ID, mg.To.ID)
+	}
+	resolved := ins.resolvedVersions(ctx, platformDir)
+	cfg.Manifest = *current
+	cfg.ManifestSource = current.Source
+	cfg.ManifestBase = current.BaseSource
+	cfg.ManifestOverlays = current.Overlays
+	cfg.ManifestKeys = current.TrustedKeys
+	cfg.Channel = current.ChannelName()
+	for name, v := range resolved {
+		if prev, ok := cfg.Resolved[name]; ok && prev != v {
+			ins.Log.Printf("  %s %s → %s", name, prev, v)
+		}
+	}
+	cfg.Resolved = resolved
+	// Deprecated components without a replacement stay installed.
+	for _, c := range diff.Deprecated {
+		components = append(components, c.ID)
+	}
+	cfg.Components = components
+	if err := config.Write(platformDir, cfg); err != nil {
+		return nil, err
+	}
+
+	res.Duration = time.Since(start)
+	return res, nil
+}
+
+// AddResult is returned after a successful Add.
+type AddResult struct {
+	// Unscaffolded lists plugins that could not be added to the project's
+	// kb.config.jsonc (missing file or plugins section) and need a manual entry.
+	Unscaffolded []string
+	Duration     time.Duration
+}
+
+// Add installs third-party plugins into an existing platform, records them
+// as user plugins in the config and enables them in the project config.
+// Re-adding a plugin replaces its recorded version range.
+func (ins *Installer) Add(ctx context.Context, platformDir string, plugins []manifest.Component) (*AddResult, error) {
+	start := time.Now()
+
 	cfg, err := config.Read(platformDir)
 	if err != nil {
 		return nil, err
 	}
-	cfg.Manifest = *current
+	if err := ins.configureRegistry(platformDir, nil); err != nil {
+		return nil, err
+	}
+
+	ins.step(1, 2, fmt.Sprintf("Installing %d packages via %s", len(plugins), ins.PM.Name()))
+	if err := ins.installGroup(ctx, platformDir, specs(plugins)); err != nil {
+		return nil, fmt.Errorf("install: %w", err)
+	}
+
+	ins.step(2, 2, "Writing config")
+	for _, p := range plugins {
+		if i := slices.IndexFunc(cfg.UserPlugins, func(c manifest.Component) bool { return c.ID == p.ID }); i >= 0 {
+			cfg.UserPlugins[i] = p
+		} else {
+			cfg.UserPlugins = append(cfg.UserPlugins, p)
+		}
+	}
+	cfg.Resolved = ins.resolvedVersions(ctx, platformDir)
 	if err := config.Write(platformDir, cfg); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	res := &AddResult{}
+	for _, p := range plugins {
+		ok, err := scaffold.AddPlugin(cfg.CWD, p.ID, p.ConfigDescription())
+		if err != nil {
+			return nil, fmt.Errorf("scaffold project config: %w", err)
+		}
+		if !ok {
+			res.Unscaffolded = append(res.Unscaffolded, p.ID)
+		}
+	}
+	res.Duration = time.Since(start)
+	return res, nil
+}
+
+// RemoveResult is returned after a successful Remove.
+type RemoveResult struct {
+	Component manifest.Component
+	// Unscaffolded is set when the component's entry in the project's
+	// kb.config.jsonc could not be disabled and needs a manual edit.
+	Unscaffolded bool
+	Duration     time.Duration
+}
+
+// Remove uninstalls the component with the given ID from an existing
+// platform, drops it from the recorded installation and disables it in the
+// project config. Components other installed components require are
+// refused, as are core packages.
+func (ins *Installer) Remove(ctx context.Context, platformDir, id string) (*RemoveResult, error) {
+	start := time.Now()
+
+	cfg, err := config.Read(platformDir)
+	if err != nil {
 		return nil, err
 	}
+	c, ok := cfg.Manifest.Component(id)
+	user := slices.IndexFunc(cfg.UserPlugins, func(p manifest.Component) bool { return p.ID == id })
+	switch {
+	case !ok && slices.ContainsFunc(cfg.Manifest.Core, func(p manifest.Package) bool { return p.Name == id }):
+		// Checked first: older releases recorded core packages as user plugins.
+		return nil, fmt.Errorf("%s is a core package and cannot be removed", id)
+	case user >= 0:
+		c = cfg.UserPlugins[user]
+	case !ok:
+		return nil, fmt.Errorf("unknown component %q", id)
+	case !cfg.IsInstalled(c):
+		return nil, fmt.Errorf("%s is not installed", id)
+	}
+	if by := cfg.Manifest.RequiredBy(id, installedComponents(cfg, &cfg.Manifest, nil)); len(by) > 0 {
+		return nil, fmt.Errorf("%s is required by %s; remove those first", id, strings.Join(by, ", "))
+	}
+	if err := ins.configureRegistry(platformDir, nil); err != nil {
+		return nil, err
+	}
+
+	ins.step(1, 2, fmt.Sprintf("Removing %s via %s", c.Pkg, ins.PM.Name()))
+	if err := ins.uninstallGroup(ctx, platformDir, []string{c.Pkg}); err != nil {
+		return nil, fmt.Errorf("uninstall: %w", err)
+	}
 
-	return &UpdateResult{Diff: diff, Duration: time.Since(start)}, nil
+	ins.step(2, 2, "Writing config")
+	if user >= 0 {
+		cfg.UserPlugins = slices.Delete(cfg.UserPlugins, user, user+1)
+	}
+	for k := range cfg.Ports {
+		if k == id || strings.HasPrefix(k, id+".") {
+			delete(cfg.Ports, k)
+		}
+	}
+	// Keep the recorded versions when they can't be listed.
+	if resolved := ins.resolvedVersions(ctx, platformDir); len(resolved) > 0 {
+		cfg.Resolved = resolved
+	}
+	delete(cfg.Resolved, c.Pkg)
+	// Configs written before the installed components were recorded get
+	// them now, so update doesn't bring the removed one back.
+	if cfg.Components == nil {
+		ids := []string{}
+		for _, m := range append(append([]manifest.Component{}, cfg.Manifest.Services...), cfg.Manifest.Plugins...) {
+			if cfg.IsInstalled(m) {
+				ids = append(ids, m.ID)
+			}
+		}
+		cfg.Components = ids
+	}
+	cfg.Components = slices.DeleteFunc(cfg.Components, func(s string) bool { return s == id })
+	if err := config.Write(platformDir, cfg); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	ok, err = scaffold.DisableComponent(cfg.CWD, id)
+	if err != nil {
+		return nil, fmt.Errorf("scaffold project config: %w", err)
+	}
+	return &RemoveResult{Component: c, Unscaffolded: !ok, Duration: time.Since(start)}, nil
 }
 
 // ── helpers ──────────────────────────────────────────────────────────────────
@@ -171,41 +522,127 @@ func (ins *Installer) step(n, total int, label string) {
 	}
 }
 
+// configureRegistry points the package manager at m's registries, or at the
+// ones recorded in dir when m is nil, and redacts their auth tokens from the
+// log.
+func (ins *Installer) configureRegistry(dir string, m *manifest.Manifest) error {
+	var u string
+	var scopes map[string]string
+	if m != nil {
+		u, scopes = m.RegistryURL, m.Scopes
+		if scopes == nil {
+			scopes = map[string]string{} // drop scopes the manifest no longer lists
+		}
+	}
+	reg, err := pm.ConfigureRegistry(dir, u, scopes)
+	if err != nil {
+		return fmt.Errorf("configure registry: %w", err)
+	}
+	ins.Log.Redact(reg.Secrets()...)
+	return nil
+}
+
 // installGroup installs pkgs into dir, draining progress lines to the log
-// and forwarding each line to OnLine if set.
+// and forwarding each line to OnLine and each package event to OnProgress.
 // It waits for the drain goroutine to finish before returning so no output
 // is lost even when the channel is buffered.
-func (ins *Installer) installGroup(dir string, pkgs []string) error {
-	return ins.runGroup(dir, pkgs, ins.PM.Install)
+func (ins *Installer) installGroup(ctx context.Context, dir string, pkgs []string) error {
+	return ins.runGroup(ctx, dir, pkgs, ins.PM.Install)
 }
 
 // updateGroup updates pkgs in dir, draining progress lines to the log.
-func (ins *Installer) updateGroup(dir string, pkgs []string) error {
-	return ins.runGroup(dir, pkgs, ins.PM.Update)
+func (ins *Installer) updateGroup(ctx context.Context, dir string, pkgs []string) error {
+	return ins.runGroup(ctx, dir, pkgs, ins.PM.Update)
+}
+
+// uninstallGroup removes pkgs from dir, draining progress lines to the log.
+func (ins *Installer) uninstallGroup(ctx context.Context, dir string, pkgs []string) error {
+	return ins.runGroup(ctx, dir, pkgs, ins.PM.Uninstall)
 }
 
 // runGroup is the shared driver for installGroup / updateGroup.
-func (ins *Installer) runGroup(dir string, pkgs []string, op func(string, []string, chan<- pm.Progress) error) error {
+func (ins *Installer) runGroup(ctx context.Context, dir string, pkgs []string, op func(context.Context, string, []string, chan<- pm.Progress) error) error {
 	ch := make(chan pm.Progress, 64)
 	done := make(chan struct{})
 	go func() {
 		defer close(done)
 		for p := range ch {
+			if p.Package != "" && ins.OnProgress != nil {
+				ins.OnProgress(p)
+			}
 			if p.Line == "" {
 				continue
 			}
 			ins.Log.Printf("  %s", p.Line)
 			if ins.OnLine != nil {
-				ins.OnLine(p.Line)
+				ins.OnLine(ins.Log.Scrub(p.Line))
 			}
 		}
 	}()
-	err := op(dir, pkgs, ch)
+	err := op(ctx, dir, pkgs, ch)
 	close(ch)
 	<-done // wait for drain goroutine to flush all buffered lines
 	return err
 }
 
+// resolveSelection expands sel with every component required by a selected
+// one and rejects conflicting combinations. Pulled-in components are added to
+// sel.Services or sel.Plugins so the scaffold enables them too.
+func (ins *Installer) resolveSelection(sel *Selection, m *manifest.Manifest) error {
+	requested := append(append([]string{}, sel.Services...), sel.Plugins...)
+	resolved, err := m.Resolve(requested)
+	if err != nil {
+		return fmt.Errorf("resolve components: %w", err)
+	}
+
+	want := make(map[string]bool, len(requested))
+	for _, id := range requested {
+		want[id] = true
+	}
+	for _, id := range resolved {
+		if want[id] {
+			continue
+		}
+		ins.Log.Printf("Adding %s (required by %s)", id, strings.Join(m.RequiredBy(id, resolved), ", "))
+		if m.IsService(id) {
+			sel.Services = append(sel.Services, id)
+		} else {
+			sel.Plugins = append(sel.Plugins, id)
+		}
+	}
+	return nil
+}
+
+// checkConstraints refuses the install up front if the host doesn't meet the
+// manifest's or any selected component's node/OS/arch constraints.
+func (ins *Installer) checkConstraints(sel *Selection, m *manifest.Manifest) error {
+	if ins.Host == nil {
+		return nil
+	}
+	if err := m.Constraints.Check(*ins.Host); err != nil {
+		return fmt.Errorf("platform %w", err)
+	}
+	var problems []string
+	for _, id := range append(append([]string{}, sel.Services...), sel.Plugins...) {
+		if err := m.CheckComponent(id, *ins.Host); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("incompatible components: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// installSpecs returns every package to install for sel in one shot. Specs
+// carry the manifest's version range ("name@range") when pinned.
+func (ins *Installer) installSpecs(sel *Selection, m *manifest.Manifest) []string {
+	pkgs := m.CorePackageSpecs()
+	pkgs = append(pkgs, ins.selectedPkgs(m.Services, sel.Services)...)
+	pkgs = append(pkgs, ins.selectedPkgs(m.Plugins, sel.Plugins)...)
+	return append(pkgs, specs(sel.UserPlugins)...)
+}
+
 func (ins *Installer) selectedPkgs(components []manifest.Component, ids []string) []string {
 	set := make(map[string]bool, len(ids))
 	for _, id := range ids {
@@ -214,19 +651,163 @@ func (ins *Installer) selectedPkgs(components []manifest.Component, ids []string
 	var out []string
 	for _, c := range components {
 		if set[c.ID] {
-			out = append(out, c.Pkg)
+			out = append(out, c.Spec())
 		}
 	}
 	return out
 }
 
-func pkgSet(m manifest.Manifest) map[string]bool {
-	s := make(map[string]bool)
+// resolvedVersions asks the package manager which exact versions ended up in
+// dir/node_modules. A listing failure is logged but never fails the install —
+// the config simply records no versions.
+func (ins *Installer) resolvedVersions(ctx context.Context, dir string) map[string]string {
+	pkgs, err := ins.PM.ListInstalled(ctx, dir)
+	if err != nil {
+		ins.Log.Printf("warning: could not list installed packages: %v", err)
+		return nil
+	}
+	if len(pkgs) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(pkgs))
+	for _, p := range pkgs {
+		out[p.Name] = p.Version
+	}
+	return out
+}
+
+// versionChanges returns the packages among pkgs that are installed in dir
+// and either have a new version range in ranged (package → range) or a newer
+// version within their range, sorted by name. If either package manager
+// query fails, every package is listed with the versions that are unknown
+// left empty, so that update still offers to run.
+func (ins *Installer) versionChanges(ctx context.Context, dir string, pkgs []string, ranged map[string]string) []VersionChange {
+	slices.Sort(pkgs)
+	change := func(name, from, to string) VersionChange {
+		r, ok := ranged[name]
+		if !ok {
+			return VersionChange{Name: name, From: from, To: to}
+		}
+		to = r
+		if to == "" {
+			to = "latest"
+		}
+		return VersionChange{Name: name, From: from, To: to, Spec: manifest.Package{Name: name, Version: r}.Spec()}
+	}
+	unknown := func(versions map[string]string) []VersionChange {
+		var out []VersionChange
+		for _, name := range pkgs {
+			if v, ok := versions[name]; ok || versions == nil {
+				out = append(out, change(name, v, ""))
+			}
+		}
+		return out
+	}
+
+	list, err := ins.PM.ListInstalled(ctx, dir)
+	if err != nil {
+		ins.Log.Printf("warning: could not list installed packages: %v", err)
+		return unknown(nil)
+	}
+	versions := make(map[string]string, len(list))
+	for _, p := range list {
+		versions[p.Name] = p.Version
+	}
+	var names []string
+	for _, name := range pkgs {
+		if _, ok := versions[name]; ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	// Packages moving to a new range are listed whatever is outdated.
+	var query []string
+	for _, name := range names {
+		if _, ok := ranged[name]; !ok {
+			query = append(query, name)
+		}
+	}
+	wanted := make(map[string]string)
+	if len(query) > 0 {
+		outdated, err := ins.PM.Outdated(ctx, dir, query)
+		if err != nil {
+			ins.Log.Printf("warning: could not check for newer versions: %v", err)
+			return unknown(versions)
+		}
+		for _, p := range outdated {
+			wanted[p.Name] = p.Wanted
+		}
+	}
+	var out []VersionChange
+	for _, name := range names {
+		if _, ok := ranged[name]; ok {
+			out = append(out, change(name, versions[name], ""))
+		} else if to := wanted[name]; to != "" && to != versions[name] {
+			out = append(out, change(name, versions[name], to))
+		}
+	}
+	return out
+}
+
+// installedComponents returns the IDs of the components of m that cfg
+// records as installed or whose package is about to be added. Deprecated
+// components are left out. The result is never nil.
+func installedComponents(cfg *config.PlatformConfig, m *manifest.Manifest, added []string) []string {
+	ids := []string{}
+	for _, c := range append(append([]manifest.Component{}, m.Services...), m.Plugins...) {
+		if !c.IsDeprecated() && (cfg.IsInstalled(c) || slices.Contains(added, c.Pkg)) {
+			ids = append(ids, c.ID)
+		}
+	}
+	return ids
+}
+
+// renamePortKeys moves port overrides of service from to service to.
+func renamePortKeys(ports map[string]int, from, to string) {
+	var keys []string
+	for k := range ports {
+		if k == from || strings.HasPrefix(k, from+".") {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		ports[to+strings.TrimPrefix(k, from)] = ports[k]
+		delete(ports, k)
+	}
+}
+
+// specs returns the install specs of components.
+func specs(components []manifest.Component) []string {
+	out := make([]string, len(components))
+	for i, c := range components {
+		out[i] = c.Spec()
+	}
+	return out
+}
+
+// pkgNames returns the package names of components.
+func pkgNames(components []manifest.Component) []string {
+	out := make([]string, len(components))
+	for i, c := range components {
+		out[i] = c.Pkg
+	}
+	return out
+}
+
+// pkgRanges maps the package of every core package and non-deprecated
+// component of m to its version range ("" for latest).
+func pkgRanges(m manifest.Manifest) map[string]string {
+	s := make(map[string]string)
 	for _, p := range m.Core {
-		s[p.Name] = true
+		s[p.Name] = p.Version
 	}
 	for _, c := range append(m.Services, m.Plugins...) {
-		s[c.Pkg] = true
+		if !c.IsDeprecated() {
+			s[c.Pkg] = c.Version
+		}
 	}
 	return s
 }
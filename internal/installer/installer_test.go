@@ -1,7 +1,12 @@
 package installer
 
 import (
+	"context"
+	"errors"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/kb-labs/create/internal/config"
@@ -14,33 +19,54 @@ import (
 
 // fakePM is a no-op package manager for use in tests.
 type fakePM struct {
-	failErr error
-	name    string
-	failOn  string
-	calls   []string
+	failErr     error
+	name        string
+	failOn      string
+	calls       []string
+	installed   []pm.InstalledPackage
+	outdated    []pm.OutdatedPackage
+	outdatedErr error
+	events      []pm.Progress // sent on the progress channel by Install
 }
 
 func (f *fakePM) Name() string { return f.name }
 
-func (f *fakePM) Install(dir string, pkgs []string, ch chan<- pm.Progress) error {
+func (f *fakePM) Install(ctx context.Context, dir string, pkgs []string, ch chan<- pm.Progress) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	for _, p := range pkgs {
 		f.calls = append(f.calls, "install:"+p)
 		if f.failOn == p {
 			return f.failErr
 		}
 	}
+	for _, e := range f.events {
+		ch <- e
+	}
 	return nil
 }
 
-func (f *fakePM) Update(dir string, pkgs []string, ch chan<- pm.Progress) error {
+func (f *fakePM) Update(ctx context.Context, dir string, pkgs []string, ch chan<- pm.Progress) error {
 	for _, p := range pkgs {
 		f.calls = append(f.calls, "update:"+p)
 	}
 	return nil
 }
 
-func (f *fakePM) ListInstalled(dir string) ([]pm.InstalledPackage, error) {
-	return nil, nil
+func (f *fakePM) Uninstall(ctx context.Context, dir string, pkgs []string, ch chan<- pm.Progress) error {
+	for _, p := range pkgs {
+		f.calls = append(f.calls, "uninstall:"+p)
+	}
+	return nil
+}
+
+func (f *fakePM) ListInstalled(ctx context.Context, dir string) ([]pm.InstalledPackage, error) {
+	return f.installed, nil
+}
+
+func (f *fakePM) Outdated(ctx context.Context, dir string, pkgs []string) ([]pm.OutdatedPackage, error) {
+	return f.outdated, f.outdatedErr
 }
 
 // sampleManifest returns a minimal manifest for testing.
@@ -59,6 +85,15 @@ func sampleManifest() manifest.Manifest {
 	}
 }
 
+// diffAndUpdate applies the Diff for m with Update, as kb-create update does.
+func diffAndUpdate(ins *Installer, platformDir string, m *manifest.Manifest) (*UpdateResult, error) {
+	diff, err := ins.Diff(context.Background(), platformDir, m)
+	if err != nil {
+		return nil, err
+	}
+	return ins.Update(context.Background(), platformDir, m, diff)
+}
+
 // ── selectedPkgs ─────────────────────────────────────────────────────────────
 
 // TestSelectedPkgsAll verifies that all matching IDs are returned.
@@ -140,7 +175,7 @@ func TestHasChangesRemoved(t *testing.T) {
 
 // TestHasChangesUpdated verifies that a diff with updated packages has changes.
 func TestHasChangesUpdated(t *testing.T) {
-	d := &UpdateDiff{Updated: []string{"@kb-labs/cli-bin"}}
+	d := &UpdateDiff{Updated: []VersionChange{{Name: "@kb-labs/cli-bin", From: "1.0.0", To: "1.1.0"}}}
 	if !d.HasChanges() {
 		t.Error("UpdateDiff{Updated}.HasChanges() = false, want true")
 	}
@@ -170,7 +205,7 @@ func TestDiffDetectsAddedPackage(t *testing.T) {
 	}
 
 	ins := &Installer{PM: &fakePM{name: "npm"}, Log: discardLogger()}
-	diff, err := ins.Diff(dir, &current)
+	diff, err := ins.Diff(context.Background(), dir, &current)
 	if err != nil {
 		t.Fatalf("Diff() error = %v", err)
 	}
@@ -203,7 +238,7 @@ func TestDiffDetectsRemovedPackage(t *testing.T) {
 	}
 
 	ins := &Installer{PM: &fakePM{name: "npm"}, Log: discardLogger()}
-	diff, err := ins.Diff(dir, &current)
+	diff, err := ins.Diff(context.Background(), dir, &current)
 	if err != nil {
 		t.Fatalf("Diff() error = %v", err)
 	}
@@ -213,6 +248,195 @@ func TestDiffDetectsRemovedPackage(t *testing.T) {
 	}
 }
 
+// TestDiffReportsVersionChanges verifies that only installed packages the
+// package manager reports as outdated are listed, with their versions.
+func TestDiffReportsVersionChanges(t *testing.T) {
+	dir := t.TempDir()
+	m := sampleManifest()
+	cfg := config.NewConfig(dir, dir, "npm", &m, config.TelemetryConfig{})
+	if err := config.Write(dir, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakePM{
+		name: "npm",
+		installed: []pm.InstalledPackage{
+			{Name: "@kb-labs/cli-bin", Version: "1.0.0"},
+			{Name: "@kb-labs/mind", Version: "1.2.0"},
+		},
+		outdated: []pm.OutdatedPackage{
+			{Name: "@kb-labs/mind", Current: "1.2.0", Wanted: "1.3.1", Latest: "2.0.0"},
+			{Name: "@kb-labs/cli-bin", Current: "1.0.0", Wanted: "1.0.0", Latest: "2.0.0"},
+		},
+	}
+	ins := &Installer{PM: fake, Log: discardLogger()}
+	diff, err := ins.Diff(context.Background(), dir, &m)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	want := []VersionChange{{Name: "@kb-labs/mind", From: "1.2.0", To: "1.3.1"}}
+	if !slices.Equal(diff.Updated, want) {
+		t.Errorf("Diff.Updated = %+v, want %+v", diff.Updated, want)
+	}
+
+	fake.outdated = nil
+	diff, err = ins.Diff(context.Background(), dir, &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.HasChanges() {
+		t.Errorf("Diff() = %+v, want no changes when nothing is outdated", diff)
+	}
+}
+
+// TestDiffOutdatedFailureListsInstalled verifies that a failed outdated
+// query lists every installed package with an unknown target version.
+func TestDiffOutdatedFailureListsInstalled(t *testing.T) {
+	dir := t.TempDir()
+	m := sampleManifest()
+	cfg := config.NewConfig(dir, dir, "npm", &m, config.TelemetryConfig{})
+	if err := config.Write(dir, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakePM{
+		name:        "npm",
+		installed:   []pm.InstalledPackage{{Name: "@kb-labs/mind", Version: "1.2.0"}},
+		outdatedErr: errors.New("registry unreachable"),
+	}
+	ins := &Installer{PM: fake, Log: discardLogger()}
+	diff, err := ins.Diff(context.Background(), dir, &m)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	want := []VersionChange{{Name: "@kb-labs/mind", From: "1.2.0"}}
+	if !slices.Equal(diff.Updated, want) {
+		t.Errorf("Diff.Updated = %+v, want %+v", diff.Updated, want)
+	}
+}
+
+// TestDiffReportsRangeChanges verifies that an installed package whose
+// manifest version range changed is listed with its new range, even though
+// nothing is outdated within the old one.
+func TestDiffReportsRangeChanges(t *testing.T) {
+	dir := t.TempDir()
+	installed := sampleManifest()
+	installed.Plugins[0].Version = "^1.0.0"
+	cfg := config.NewConfig(dir, dir, "npm", &installed, config.TelemetryConfig{})
+	if err := config.Write(dir, cfg); err != nil {
+		t.Fatal(err)
+	}
+	current := sampleManifest()
+	current.Plugins[0].Version = "^2.0.0"
+	current.Core[0].Version = "^3.0.0"
+
+	fake := &fakePM{name: "npm", installed: []pm.InstalledPackage{
+		{Name: "@kb-labs/cli-bin", Version: "2.4.0"},
+		{Name: "@kb-labs/mind", Version: "1.3.1"},
+	}}
+	ins := &Installer{PM: fake, Log: discardLogger()}
+	diff, err := ins.Diff(context.Background(), dir, &current)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	want := []VersionChange{
+		{Name: "@kb-labs/cli-bin", From: "2.4.0", To: "^3.0.0", Spec: "@kb-labs/cli-bin@^3.0.0"},
+		{Name: "@kb-labs/mind", From: "1.3.1", To: "^2.0.0", Spec: "@kb-labs/mind@^2.0.0"},
+	}
+	if !slices.Equal(diff.Updated, want) {
+		t.Errorf("Diff.Updated = %+v, want %+v", diff.Updated, want)
+	}
+}
+
+// TestUpdateInstallsRangeChanges verifies that packages with a new version
+// range are installed with it rather than updated within the old one.
+func TestUpdateInstallsRangeChanges(t *testing.T) {
+	platformDir := t.TempDir()
+	installed := sampleManifest()
+	cfg := config.NewConfig(platformDir, t.TempDir(), "npm", &installed, config.TelemetryConfig{})
+	if err := config.Write(platformDir, cfg); err != nil {
+		t.Fatal(err)
+	}
+	current := sampleManifest()
+	current.Plugins[0].Version = "^2.0.0"
+
+	fake := &fakePM{name: "npm", installed: []pm.InstalledPackage{{Name: "@kb-labs/mind", Version: "1.3.1"}}}
+	var labels []string
+	ins := &Installer{
+		PM:     fake,
+		Log:    discardLogger(),
+		OnStep: func(step, total int, label string) { labels = append(labels, label) },
+	}
+	if _, err := diffAndUpdate(ins, platformDir, &current); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if !slices.Contains(fake.calls, "install:@kb-labs/mind@^2.0.0") {
+		t.Errorf("calls = %v, want @kb-labs/mind@^2.0.0 installed", fake.calls)
+	}
+	if slices.ContainsFunc(fake.calls, func(c string) bool { return strings.HasPrefix(c, "update:@kb-labs/mind") }) {
+		t.Errorf("calls = %v, want @kb-labs/mind left out of the update", fake.calls)
+	}
+	want := []string{"Installing 1 packages with new version ranges via npm", "Updating 5 packages via npm", "Writing config"}
+	if strings.Join(labels, "|") != strings.Join(want, "|") {
+		t.Errorf("OnStep labels = %v, want %v", labels, want)
+	}
+}
+
+// TestUpdateInstallsAddedPackagesWithRange verifies that packages a new
+// manifest adds are installed with their version range.
+func TestUpdateInstallsAddedPackagesWithRange(t *testing.T) {
+	platformDir := t.TempDir()
+	installed := sampleManifest()
+	cfg := config.NewConfig(platformDir, t.TempDir(), "npm", &installed, config.TelemetryConfig{})
+	if err := config.Write(platformDir, cfg); err != nil {
+		t.Fatal(err)
+	}
+	current := sampleManifest()
+	current.Core = append(current.Core, manifest.Package{Name: "@kb-labs/shared", Version: "^1.4.0"})
+	current.Plugins = append(current.Plugins, manifest.Component{ID: "commit", Pkg: "@kb-labs/commit", Version: "~2.1.0"})
+
+	fake := &fakePM{name: "npm"}
+	ins := &Installer{PM: fake, Log: discardLogger()}
+	if _, err := diffAndUpdate(ins, platformDir, &current); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	for _, call := range []string{"install:@kb-labs/shared@^1.4.0", "install:@kb-labs/commit@~2.1.0"} {
+		if !slices.Contains(fake.calls, call) {
+			t.Errorf("calls = %v, want %s", fake.calls, call)
+		}
+	}
+}
+
+// TestDiffResolvesNewRequirements verifies that a component an installed
+// one newly requires is added, and that a new conflict between installed
+// components is refused.
+func TestDiffResolvesNewRequirements(t *testing.T) {
+	dir := t.TempDir()
+	installed := sampleManifest()
+	cfg := config.NewConfig(dir, dir, "npm", &installed, config.TelemetryConfig{})
+	cfg.Resolved = map[string]string{"@kb-labs/rest-api": "1.0.0", "@kb-labs/mind": "1.0.0"}
+	if err := config.Write(dir, cfg); err != nil {
+		t.Fatal(err)
+	}
+	ins := &Installer{PM: &fakePM{name: "npm"}, Log: discardLogger()}
+
+	current := sampleManifest()
+	current.Plugins[0].Requires = []string{"agents"}
+	diff, err := ins.Diff(context.Background(), dir, &current)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if !slices.Equal(diff.Added, []string{"@kb-labs/agents"}) {
+		t.Errorf("Diff.Added = %v, want [@kb-labs/agents]", diff.Added)
+	}
+
+	current = sampleManifest()
+	current.Services[0].Conflicts = []string{"mind"}
+	if _, err := ins.Diff(context.Background(), dir, &current); err == nil || !strings.Contains(err.Error(), "conflict") {
+		t.Errorf("Diff() error = %v, want a conflict", err)
+	}
+}
+
 // TestDiffNoConfigReturnsError verifies that Diff returns an error when no
 // config exists in the given directory.
 func TestDiffNoConfigReturnsError(t *testing.T) {
@@ -220,7 +444,7 @@ func TestDiffNoConfigReturnsError(t *testing.T) {
 	m := sampleManifest()
 
 	ins := &Installer{PM: &fakePM{name: "npm"}, Log: discardLogger()}
-	_, err := ins.Diff(dir, &m)
+	_, err := ins.Diff(context.Background(), dir, &m)
 	if err == nil {
 		t.Error("Diff() on missing config should return error, got nil")
 	}
@@ -244,7 +468,7 @@ func TestInstallWritesConfig(t *testing.T) {
 		Plugins:     []string{"mind"},
 	}
 
-	result, err := ins.Install(sel, &m)
+	result, err := ins.Install(context.Background(), sel, &m)
 	if err != nil {
 		t.Fatalf("Install() error = %v", err)
 	}
@@ -280,7 +504,7 @@ func TestInstallCallsCorePackages(t *testing.T) {
 		ProjectCWD:  projectDir,
 	}
 
-	if _, err := ins.Install(sel, &m); err != nil {
+	if _, err := ins.Install(context.Background(), sel, &m); err != nil {
 		t.Fatalf("Install() error = %v", err)
 	}
 
@@ -310,7 +534,7 @@ func TestInstallCreatesProjectKBDir(t *testing.T) {
 		ProjectCWD:  projectDir,
 	}
 
-	if _, err := ins.Install(sel, &m); err != nil {
+	if _, err := ins.Install(context.Background(), sel, &m); err != nil {
 		t.Fatalf("Install() error = %v", err)
 	}
 
@@ -337,7 +561,7 @@ func TestInstallInvokesOnStep(t *testing.T) {
 	m := sampleManifest()
 	sel := &Selection{PlatformDir: platformDir, ProjectCWD: projectDir}
 
-	if _, err := ins.Install(sel, &m); err != nil {
+	if _, err := ins.Install(context.Background(), sel, &m); err != nil {
 		t.Fatalf("Install() error = %v", err)
 	}
 
@@ -346,6 +570,672 @@ func TestInstallInvokesOnStep(t *testing.T) {
 	}
 }
 
+// TestInstallPassesVersionSpecs verifies that pinned manifest versions are
+// passed to the package manager as name@range specs.
+func TestInstallPassesVersionSpecs(t *testing.T) {
+	platformDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	fake := &fakePM{name: "npm"}
+	ins := &Installer{PM: fake, Log: discardLogger()}
+	m := sampleManifest()
+	m.Core[0].Version = "^1.2.0"
+	m.Plugins[0].Version = "~2.0.1"
+
+	sel := &Selection{PlatformDir: platformDir, ProjectCWD: projectDir, Plugins: []string{"mind"}}
+	if _, err := ins.Install(context.Background(), sel, &m); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range fake.calls {
+		seen[c] = true
+	}
+	for _, want := range []string{"install:@kb-labs/cli-bin@^1.2.0", "install:@kb-labs/sdk", "install:@kb-labs/mind@~2.0.1"} {
+		if !seen[want] {
+			t.Errorf("missing call %q; calls = %v", want, fake.calls)
+		}
+	}
+}
+
+// TestInstallRecordsResolvedVersions verifies that the exact versions reported
+// by the package manager are persisted in the config.
+func TestInstallRecordsResolvedVersions(t *testing.T) {
+	platformDir := t.TempDir()
+	projectDir := t.TempDir()
+
+	fake := &fakePM{name: "npm", installed: []pm.InstalledPackage{
+		{Name: "@kb-labs/cli-bin", Version: "1.2.3"},
+		{Name: "@kb-labs/sdk", Version: "0.9.0"},
+	}}
+	ins := &Installer{PM: fake, Log: discardLogger()}
+	m := sampleManifest()
+
+	if _, err := ins.Install(context.Background(), &Selection{PlatformDir: platformDir, ProjectCWD: projectDir}, &m); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	cfg, err := config.Read(platformDir)
+	if err != nil {
+		t.Fatalf("config.Read() error = %v", err)
+	}
+	if got := cfg.Resolved["@kb-labs/cli-bin"]; got != "1.2.3" {
+		t.Errorf("Resolved[cli-bin] = %q, want %q", got, "1.2.3")
+	}
+	if got := cfg.Resolved["@kb-labs/sdk"]; got != "0.9.0" {
+		t.Errorf("Resolved[sdk] = %q, want %q", got, "0.9.0")
+	}
+}
+
+// TestInstallPullsRequiredComponents verifies that components required by a
+// selected one are installed and added to the selection.
+func TestInstallPullsRequiredComponents(t *testing.T) {
+	fake := &fakePM{name: "npm"}
+	ins := &Installer{PM: fake, Log: discardLogger()}
+	m := sampleManifest()
+	m.Plugins[1].Requires = []string{"rest"} // agents → rest
+
+	sel := &Selection{PlatformDir: t.TempDir(), ProjectCWD: t.TempDir(), Plugins: []string{"agents"}}
+	if _, err := ins.Install(context.Background(), sel, &m); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	if len(sel.Services) != 1 || sel.Services[0] != "rest" {
+		t.Errorf("Selection.Services = %v, want [rest]", sel.Services)
+	}
+	found := false
+	for _, c := range fake.calls {
+		if c == "install:@kb-labs/rest-api" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("required package not installed; calls = %v", fake.calls)
+	}
+}
+
+// TestInstallRejectsConflicts verifies that conflicting components abort the
+// install before the package manager runs.
+func TestInstallRejectsConflicts(t *testing.T) {
+	fake := &fakePM{name: "npm"}
+	ins := &Installer{PM: fake, Log: discardLogger()}
+	m := sampleManifest()
+	m.Services[1].Conflicts = []string{"agents"} // studio ✗ agents
+
+	sel := &Selection{
+		PlatformDir: t.TempDir(),
+		ProjectCWD:  t.TempDir(),
+		Services:    []string{"studio"},
+		Plugins:     []string{"agents"},
+	}
+	if _, err := ins.Install(context.Background(), sel, &m); err == nil {
+		t.Fatal("Install() with conflicting components should fail")
+	}
+	if len(fake.calls) != 0 {
+		t.Errorf("PM called despite conflict: %v", fake.calls)
+	}
+}
+
+// TestInstallRejectsUnsupportedHost verifies that a component whose
+// constraints the host doesn't meet aborts the install before the package
+// manager runs.
+func TestInstallRejectsUnsupportedHost(t *testing.T) {
+	fake := &fakePM{name: "npm"}
+	host := manifest.Host{OS: "linux", Arch: "amd64", Node: "18.19.0"}
+	ins := &Installer{PM: fake, Log: discardLogger(), Host: &host}
+	m := sampleManifest()
+	m.Plugins[1].Constraints = manifest.Constraints{Node: ">=20"}
+
+	sel := &Selection{PlatformDir: t.TempDir(), ProjectCWD: t.TempDir(), Plugins: []string{"agents"}}
+	_, err := ins.Install(context.Background(), sel, &m)
+	if err == nil || !strings.Contains(err.Error(), "requires node >=20") {
+		t.Fatalf("Install() error = %v, want node constraint error", err)
+	}
+	if len(fake.calls) != 0 {
+		t.Errorf("PM called despite unmet constraint: %v", fake.calls)
+	}
+}
+
+// TestInstallRecordsPortOverrides verifies that port overrides are stored in
+// the platform config and unknown ones are rejected.
+func TestInstallRecordsPortOverrides(t *testing.T) {
+	platformDir := t.TempDir()
+	ins := &Installer{PM: &fakePM{name: "npm"}, Log: discardLogger()}
+	m := sampleManifest()
+	m.Services[0].Ports = []manifest.Port{{Port: 5050}} // rest
+
+	bad := &Selection{PlatformDir: platformDir, ProjectCWD: t.TempDir(), Ports: map[string]int{"studio": 1}}
+	if _, err := ins.Install(context.Background(), bad, &m); err == nil {
+		t.Fatal("Install() with unknown port override should fail")
+	}
+
+	sel := &Selection{PlatformDir: platformDir, ProjectCWD: t.TempDir(), Services: []string{"rest"}, Ports: map[string]int{"rest": 5051}}
+	if _, err := ins.Install(context.Background(), sel, &m); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	cfg, err := config.Read(platformDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Ports["rest"] != 5051 {
+		t.Errorf("config Ports = %v, want rest=5051", cfg.Ports)
+	}
+}
+
+// TestInstallInterruptedWritesNoConfig verifies that a cancelled install
+// stops with context.Canceled before anything is recorded, so running it
+// again starts over.
+func TestInstallInterruptedWritesNoConfig(t *testing.T) {
+	platformDir, projectDir := t.TempDir(), t.TempDir()
+	ins := &Installer{PM: &fakePM{name: "npm"}, Log: discardLogger()}
+	m := sampleManifest()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := ins.Install(ctx, &Selection{PlatformDir: platformDir, ProjectCWD: projectDir}, &m)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Install() error = %v, want context.Canceled", err)
+	}
+	if _, err := os.Stat(config.ConfigPath(platformDir)); !os.IsNotExist(err) {
+		t.Error("config written by an interrupted install")
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, ".kb")); !os.IsNotExist(err) {
+		t.Error("project config written by an interrupted install")
+	}
+}
+
+// ── post-install ─────────────────────────────────────────────────────────────
+
+// TestInstallGroupForwardsPackageEvents verifies that package events reach
+// OnProgress and stay out of OnLine, while plain lines reach OnLine only.
+func TestInstallGroupForwardsPackageEvents(t *testing.T) {
+	fpm := &fakePM{name: "pnpm", events: []pm.Progress{
+		{Package: "left-pad@1.3.0", Phase: pm.PhaseResolved},
+		{Package: "left-pad@1.3.0", Phase: pm.PhaseLinked, Done: true},
+		{Line: "added 1 package"},
+	}}
+	var events []pm.Progress
+	var lines []string
+	ins := &Installer{
+		PM:         fpm,
+		Log:        discardLogger(),
+		OnLine:     func(line string) { lines = append(lines, line) },
+		OnProgress: func(p pm.Progress) { events = append(events, p) },
+	}
+
+	if err := ins.installGroup(context.Background(), t.TempDir(), []string{"left-pad"}); err != nil {
+		t.Fatalf("installGroup() error = %v", err)
+	}
+	if len(events) != 2 || !events[1].Done {
+		t.Errorf("OnProgress events = %+v, want resolved and linked left-pad", events)
+	}
+	if len(lines) != 1 || lines[0] != "added 1 package" {
+		t.Errorf("OnLine lines = %v, want [added 1 package]", lines)
+	}
+}
+
+// TestInstallGroupRedactsLines verifies that registry secrets are masked in
+// the lines forwarded to OnLine, which the CLI prints without the logger.
+func TestInstallGroupRedactsLines(t *testing.T) {
+	fpm := &fakePM{name: "npm", events: []pm.Progress{{Line: "GET https://npm.corp.example/ token npm_s3cr3t"}}}
+	var lines []string
+	ins := &Installer{
+		PM:     fpm,
+		Log:    discardLogger(),
+		OnLine: func(line string) { lines = append(lines, line) },
+	}
+	ins.Log.Redact("npm_s3cr3t")
+
+	if err := ins.installGroup(context.Background(), t.TempDir(), []string{"left-pad"}); err != nil {
+		t.Fatalf("installGroup() error = %v", err)
+	}
+	if len(lines) != 1 || lines[0] != "GET https://npm.corp.example/ token ***" {
+		t.Errorf("OnLine lines = %q, want the token redacted", lines)
+	}
+}
+
+// TestInstallConfiguresRegistry verifies that the manifest registry and an
+// auth token from the environment reach the platform's .npmrc, and that the
+// token is redacted from the install log.
+func TestInstallConfiguresRegistry(t *testing.T) {
+	platformDir := t.TempDir()
+	t.Setenv(pm.TokenEnv, "s3cr3t-token")
+
+	log, err := logger.New(platformDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakePM{name: "npm", events: []pm.Progress{{Line: "GET https://npm.corp.example/ token=s3cr3t-token"}}}
+	ins := &Installer{PM: fake, Log: log}
+	m := sampleManifest()
+	m.RegistryURL = "https://npm.corp.example"
+
+	sel := &Selection{PlatformDir: platformDir, ProjectCWD: t.TempDir()}
+	if _, err := ins.Install(context.Background(), sel, &m); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// #nosec G304 -- reads files written under the test's temp dir.
+	npmrc, err := os.ReadFile(filepath.Join(platformDir, ".npmrc"))
+	if err != nil || !strings.Contains(string(npmrc), "registry=https://npm.corp.example\n") ||
+		!strings.Contains(string(npmrc), "//npm.corp.example/:_authToken=s3cr3t-token") {
+		t.Errorf(".npmrc = %q, %v; want registry and token", npmrc, err)
+	}
+	// #nosec G304 -- reads files written under the test's temp dir.
+	data, err := os.ReadFile(log.LogPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "s3cr3t-token") {
+		t.Errorf("install log contains the token: %q", data)
+	}
+}
+
+// TestInstallRunsPostInstallSteps verifies that post-install steps of selected
+// components run as extra numbered stages and stream their output.
+func TestInstallRunsPostInstallSteps(t *testing.T) {
+	var labels, lines []string
+	ins := &Installer{
+		PM:     &fakePM{name: "npm"},
+		Log:    discardLogger(),
+		OnStep: func(step, total int, label string) { labels = append(labels, label) },
+		OnLine: func(line string) { lines = append(lines, line) },
+	}
+	m := sampleManifest()
+	m.Plugins[0].PostInstall = []manifest.Step{{Name: "Build index", Run: []string{"sh", "-c", "echo indexed"}}}
+	m.Plugins[1].PostInstall = []manifest.Step{{Name: "Not selected", Run: []string{"false"}}}
+
+	sel := &Selection{PlatformDir: t.TempDir(), ProjectCWD: t.TempDir(), Plugins: []string{"mind"}}
+	if _, err := ins.Install(context.Background(), sel, &m); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	if len(labels) != 3 || labels[1] != "Build index (mind)" {
+		t.Errorf("OnStep labels = %v, want post-install step second of 3", labels)
+	}
+	if len(lines) != 1 || lines[0] != "indexed" {
+		t.Errorf("OnLine lines = %v, want [indexed]", lines)
+	}
+}
+
+// TestInstallStopsOnFailedPostInstall verifies that a failing step aborts the
+// install with its name and no config is written.
+func TestInstallStopsOnFailedPostInstall(t *testing.T) {
+	platformDir := t.TempDir()
+	ins := &Installer{PM: &fakePM{name: "npm"}, Log: discardLogger()}
+	m := sampleManifest()
+	m.Plugins[0].PostInstall = []manifest.Step{{Name: "Migrate", Run: []string{"sh", "-c", "exit 3"}}}
+
+	sel := &Selection{PlatformDir: platformDir, ProjectCWD: t.TempDir(), Plugins: []string{"mind"}}
+	_, err := ins.Install(context.Background(), sel, &m)
+	if err == nil || !strings.Contains(err.Error(), `post-install "Migrate" for mind`) {
+		t.Fatalf("Install() error = %v, want post-install failure", err)
+	}
+	if _, statErr := os.Stat(config.ConfigPath(platformDir)); !os.IsNotExist(statErr) {
+		t.Error("config written despite failed post-install step")
+	}
+}
+
+// TestUpdateRunsPostInstallOfInstalledComponents verifies that Update reruns
+// steps only for components whose package is recorded as installed.
+func TestUpdateRunsPostInstallOfInstalledComponents(t *testing.T) {
+	platformDir := t.TempDir()
+	m := sampleManifest()
+	cfg := config.NewConfig(platformDir, t.TempDir(), "npm", &m, config.TelemetryConfig{})
+	cfg.Resolved = map[string]string{"@kb-labs/mind": "1.0.0"}
+	if err := config.Write(platformDir, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	var labels []string
+	ins := &Installer{
+		PM:     &fakePM{name: "npm"},
+		Log:    discardLogger(),
+		OnStep: func(step, total int, label string) { labels = append(labels, label) },
+	}
+	m.Plugins[0].PostInstall = []manifest.Step{{Name: "Build index", Run: []string{"true"}}}
+	m.Plugins[1].PostInstall = []manifest.Step{{Name: "Not installed", Run: []string{"false"}}}
+
+	if _, err := diffAndUpdate(ins, platformDir, &m); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	want := []string{"Updating 3 packages via npm", "Build index (mind)", "Writing config"}
+	if strings.Join(labels, "|") != strings.Join(want, "|") {
+		t.Errorf("OnStep labels = %v, want %v", labels, want)
+	}
+}
+
+// ── user plugins ─────────────────────────────────────────────────────────────
+
+// TestInstallRecordsUserPlugins verifies that third-party plugins are
+// installed, recorded in the config and enabled in the project config.
+func TestInstallRecordsUserPlugins(t *testing.T) {
+	platformDir, projectDir := t.TempDir(), t.TempDir()
+	fake := &fakePM{name: "npm"}
+	ins := &Installer{PM: fake, Log: discardLogger()}
+	m := sampleManifest()
+	foo := manifest.Component{ID: "@acme/foo", Pkg: "@acme/foo", Version: "^1.0.0"}
+
+	sel := &Selection{PlatformDir: platformDir, ProjectCWD: projectDir, UserPlugins: []manifest.Component{foo}}
+	if _, err := ins.Install(context.Background(), sel, &m); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if !slices.Contains(fake.calls, "install:@acme/foo@^1.0.0") {
+		t.Errorf("calls = %v, want @acme/foo@^1.0.0 installed", fake.calls)
+	}
+	cfg, err := config.Read(platformDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.UserPlugins) != 1 || cfg.UserPlugins[0].ID != "@acme/foo" {
+		t.Errorf("config UserPlugins = %+v", cfg.UserPlugins)
+	}
+	// #nosec G304 -- test reads a file created under its own temp project dir.
+	data, err := os.ReadFile(filepath.Join(projectDir, ".kb", "kb.config.jsonc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "\"@acme/foo\": {\n      \"enabled\": true") {
+		t.Error("project config has no enabled entry for @acme/foo")
+	}
+}
+
+// TestUpdateKeepsUserPlugins verifies that Update updates recorded user
+// plugins along with the manifest and keeps them in the config.
+func TestUpdateKeepsUserPlugins(t *testing.T) {
+	platformDir := t.TempDir()
+	m := sampleManifest()
+	cfg := config.NewConfig(platformDir, t.TempDir(), "npm", &m, config.TelemetryConfig{})
+	cfg.UserPlugins = []manifest.Component{{ID: "@acme/foo", Pkg: "@acme/foo"}}
+	if err := config.Write(platformDir, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakePM{name: "npm"}
+	ins := &Installer{PM: fake, Log: discardLogger()}
+	if _, err := diffAndUpdate(ins, platformDir, &m); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if !slices.Contains(fake.calls, "update:@acme/foo") {
+		t.Errorf("calls = %v, want @acme/foo updated", fake.calls)
+	}
+	got, err := config.Read(platformDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.UserPlugins) != 1 {
+		t.Errorf("config UserPlugins = %+v, want @acme/foo kept", got.UserPlugins)
+	}
+}
+
+// TestAddInstallsAndRecordsPlugins verifies that Add installs the plugin,
+// replaces an earlier entry for the same ID and enables it in the project.
+func TestAddInstallsAndRecordsPlugins(t *testing.T) {
+	platformDir, projectDir := t.TempDir(), t.TempDir()
+	m := sampleManifest()
+	fake := &fakePM{name: "npm"}
+	ins := &Installer{PM: fake, Log: discardLogger()}
+	if _, err := ins.Install(context.Background(), &Selection{PlatformDir: platformDir, ProjectCWD: projectDir}, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, version := range []string{"^1.0.0", "^2.0.0"} {
+		foo := manifest.Component{ID: "@acme/foo", Pkg: "@acme/foo", Version: version}
+		res, err := ins.Add(context.Background(), platformDir, []manifest.Component{foo})
+		if err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+		if len(res.Unscaffolded) != 0 {
+			t.Errorf("Unscaffolded = %v, want none", res.Unscaffolded)
+		}
+	}
+	if !slices.Contains(fake.calls, "install:@acme/foo@^2.0.0") {
+		t.Errorf("calls = %v, want @acme/foo@^2.0.0 installed", fake.calls)
+	}
+	cfg, err := config.Read(platformDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.UserPlugins) != 1 || cfg.UserPlugins[0].Version != "^2.0.0" {
+		t.Errorf("config UserPlugins = %+v, want a single @acme/foo@^2.0.0", cfg.UserPlugins)
+	}
+	// #nosec G304 -- test reads a file created under its own temp project dir.
+	data, err := os.ReadFile(filepath.Join(projectDir, ".kb", "kb.config.jsonc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "\"@acme/foo\": {"); n != 1 {
+		t.Errorf("project config has %d entries for @acme/foo, want 1", n)
+	}
+}
+
+// ── remove ───────────────────────────────────────────────────────────────────
+
+// TestRemoveUninstallsAndDisables verifies that Remove uninstalls the
+// package, drops it from the recorded versions, disables it in the
+// project config and keeps Update from installing it again.
+func TestRemoveUninstallsAndDisables(t *testing.T) {
+	platformDir, projectDir := t.TempDir(), t.TempDir()
+	m := sampleManifest()
+	fake := &fakePM{name: "npm", installed: []pm.InstalledPackage{
+		{Name: "@kb-labs/cli-bin", Version: "1.0.0"},
+		{Name: "@kb-labs/rest-api", Version: "1.0.0"},
+		{Name: "@kb-labs/mind", Version: "1.0.0"},
+	}}
+	ins := &Installer{PM: fake, Log: discardLogger()}
+	sel := &Selection{PlatformDir: platformDir, ProjectCWD: projectDir, Services: []string{"rest"}, Plugins: []string{"mind"}}
+	if _, err := ins.Install(context.Background(), sel, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	fake.calls = nil
+	fake.installed = fake.installed[:2]
+	res, err := ins.Remove(context.Background(), platformDir, "mind")
+	if err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if res.Unscaffolded {
+		t.Error("Unscaffolded = true, want the project entry disabled")
+	}
+	if !slices.Equal(fake.calls, []string{"uninstall:@kb-labs/mind"}) {
+		t.Errorf("calls = %v, want only @kb-labs/mind uninstalled", fake.calls)
+	}
+	cfg, err := config.Read(platformDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cfg.Resolved["@kb-labs/mind"]; ok {
+		t.Errorf("Resolved = %v, want @kb-labs/mind dropped", cfg.Resolved)
+	}
+	// #nosec G304 -- test reads a file created under its own temp project dir.
+	data, err := os.ReadFile(filepath.Join(projectDir, ".kb", "kb.config.jsonc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "\"mind\": {\n      \"enabled\": false") {
+		t.Errorf("project config does not disable mind:\n%s", data)
+	}
+
+	fake.calls = nil
+	if _, err := diffAndUpdate(ins, platformDir, &m); err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(fake.calls, "update:@kb-labs/mind") {
+		t.Errorf("calls = %v, want removed @kb-labs/mind left out of the update", fake.calls)
+	}
+}
+
+// TestRemoveThenUpdateWithoutRecordedVersions verifies that a component
+// removed from a platform whose config predates recorded versions and
+// components stays removed across an update.
+func TestRemoveThenUpdateWithoutRecordedVersions(t *testing.T) {
+	platformDir := t.TempDir()
+	m := sampleManifest()
+	cfg := config.NewConfig(platformDir, t.TempDir(), "npm", &m, config.TelemetryConfig{})
+	if err := config.Write(platformDir, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakePM{name: "npm"}
+	ins := &Installer{PM: fake, Log: discardLogger()}
+	if _, err := ins.Remove(context.Background(), platformDir, "mind"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	cfg, err := config.Read(platformDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(cfg.Components, "mind") || !slices.Contains(cfg.Components, "rest") {
+		t.Errorf("Components = %v, want every component but mind", cfg.Components)
+	}
+
+	fake.calls = nil
+	if _, err := diffAndUpdate(ins, platformDir, &m); err != nil {
+		t.Fatal(err)
+	}
+	for _, call := range fake.calls {
+		if strings.Contains(call, "@kb-labs/mind") {
+			t.Errorf("calls = %v, want removed @kb-labs/mind left out of the update", fake.calls)
+		}
+	}
+	if cfg, err = config.Read(platformDir); err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(cfg.Components, "mind") {
+		t.Errorf("Components after update = %v, want mind still removed", cfg.Components)
+	}
+}
+
+// TestRemoveRefusesRequiredComponent verifies that a component another
+// installed component requires is not removed.
+func TestRemoveRefusesRequiredComponent(t *testing.T) {
+	platformDir := t.TempDir()
+	m := sampleManifest()
+	m.Plugins[1].Requires = []string{"mind"}
+	cfg := config.NewConfig(platformDir, t.TempDir(), "npm", &m, config.TelemetryConfig{})
+	cfg.Resolved = map[string]string{"@kb-labs/mind": "1.0.0", "@kb-labs/agents": "1.0.0"}
+	if err := config.Write(platformDir, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakePM{name: "npm"}
+	ins := &Installer{PM: fake, Log: discardLogger()}
+	_, err := ins.Remove(context.Background(), platformDir, "mind")
+	if err == nil || !strings.Contains(err.Error(), "required by agents") {
+		t.Fatalf("Remove() error = %v, want required by agents", err)
+	}
+	if len(fake.calls) != 0 {
+		t.Errorf("calls = %v, want none", fake.calls)
+	}
+}
+
+// TestRemoveRejectsUnknownAndMissing verifies that unknown IDs, core
+// packages and components that aren't installed are rejected.
+func TestRemoveRejectsUnknownAndMissing(t *testing.T) {
+	platformDir := t.TempDir()
+	m := sampleManifest()
+	cfg := config.NewConfig(platformDir, t.TempDir(), "npm", &m, config.TelemetryConfig{})
+	cfg.Resolved = map[string]string{"@kb-labs/mind": "1.0.0"}
+	// Older releases accepted core packages as user plugins.
+	cfg.UserPlugins = []manifest.Component{{ID: "@kb-labs/cli-bin", Pkg: "@kb-labs/cli-bin"}}
+	if err := config.Write(platformDir, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	ins := &Installer{PM: &fakePM{name: "npm"}, Log: discardLogger()}
+	for id, want := range map[string]string{
+		"nope":             "unknown component",
+		"@kb-labs/cli-bin": "core package",
+		"agents":           "not installed",
+	} {
+		if _, err := ins.Remove(context.Background(), platformDir, id); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Remove(%q) error = %v, want %q", id, err, want)
+		}
+	}
+}
+
+// ── deprecations ─────────────────────────────────────────────────────────────
+
+// migrationManifests returns an installed manifest with a commit-cli plugin
+// and a newer one that deprecates it in favour of commit.
+func migrationManifests() (installed, current manifest.Manifest) {
+	installed = sampleManifest()
+	installed.Plugins = append(installed.Plugins, manifest.Component{ID: "commit-cli", Pkg: "@kb-labs/commit-cli", Description: "Commit helper"})
+	current = sampleManifest()
+	current.Plugins = append(current.Plugins,
+		manifest.Component{ID: "commit-cli", Pkg: "@kb-labs/commit-cli", Description: "Commit helper", Deprecated: "renamed to commit", ReplacedBy: "commit"},
+		manifest.Component{ID: "commit", Pkg: "@kb-labs/commit", Version: "^2.0.0", Description: "AI commits"},
+	)
+	return installed, current
+}
+
+// TestDiffReportsMigrations verifies that an installed deprecated component
+// is listed as a migration rather than as removed.
+func TestDiffReportsMigrations(t *testing.T) {
+	platformDir := t.TempDir()
+	installed, current := migrationManifests()
+	cfg := config.NewConfig(platformDir, t.TempDir(), "npm", &installed, config.TelemetryConfig{})
+	if err := config.Write(platformDir, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	ins := &Installer{PM: &fakePM{name: "npm"}, Log: discardLogger()}
+	diff, err := ins.Diff(context.Background(), platformDir, &current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Migrated) != 1 || diff.Migrated[0].From.ID != "commit-cli" || diff.Migrated[0].To.ID != "commit" {
+		t.Errorf("Migrated = %+v, want commit-cli → commit", diff.Migrated)
+	}
+	if len(diff.Removed) != 0 {
+		t.Errorf("Removed = %v, want none", diff.Removed)
+	}
+}
+
+// TestUpdateMigratesDeprecatedComponent verifies that Update installs the
+// replacement, uninstalls the deprecated package and renames its entry in
+// the project config.
+func TestUpdateMigratesDeprecatedComponent(t *testing.T) {
+	platformDir, projectDir := t.TempDir(), t.TempDir()
+	installed, current := migrationManifests()
+	fake := &fakePM{name: "npm"}
+	ins := &Installer{PM: fake, Log: discardLogger()}
+	sel := &Selection{PlatformDir: platformDir, ProjectCWD: projectDir, Plugins: []string{"commit-cli"}}
+	if _, err := ins.Install(context.Background(), sel, &installed); err != nil {
+		t.Fatal(err)
+	}
+
+	fake.calls = nil
+	res, err := diffAndUpdate(ins, platformDir, &current)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	for _, call := range []string{"install:@kb-labs/commit@^2.0.0", "uninstall:@kb-labs/commit-cli"} {
+		if !slices.Contains(fake.calls, call) {
+			t.Errorf("calls = %v, want %s", fake.calls, call)
+		}
+	}
+	if slices.Contains(fake.calls, "update:@kb-labs/commit-cli") {
+		t.Error("deprecated package was updated")
+	}
+	if len(res.Unscaffolded) != 0 {
+		t.Errorf("Unscaffolded = %v, want none", res.Unscaffolded)
+	}
+
+	// #nosec G304 -- test reads a file created under its own temp project dir.
+	data, err := os.ReadFile(filepath.Join(projectDir, ".kb", "kb.config.jsonc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if strings.Contains(content, `"commit-cli"`) || !strings.Contains(content, "// AI commits\n    \"commit\": {\n      \"enabled\": true") {
+		t.Errorf("project config not migrated:\n%s", content)
+	}
+}
+
 // ── helpers ───────────────────────────────────────────────────────────────────
 
 // discardLogger returns a logger that throws away all output.
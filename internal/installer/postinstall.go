@@ -0,0 +1,100 @@
+package installer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kb-labs/create/internal/manifest"
+	"github.com/kb-labs/create/internal/pm"
+)
+
+// postStep is a manifest post-install step bound to its component.
+type postStep struct {
+	component string
+	step      manifest.Step
+}
+
+// postInstallSteps returns the post-install steps of the given components in
+// manifest order (services first, then plugins).
+func postInstallSteps(m *manifest.Manifest, ids []string) []postStep {
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	var steps []postStep
+	for _, c := range append(append([]manifest.Component{}, m.Services...), m.Plugins...) {
+		if !want[c.ID] {
+			continue
+		}
+		for _, s := range c.PostInstall {
+			steps = append(steps, postStep{component: c.ID, step: s})
+		}
+	}
+	return steps
+}
+
+// runPostInstall runs steps in dir as numbered stages starting at first.
+// Output is streamed to the log and OnLine; the first failing step aborts.
+func (ins *Installer) runPostInstall(ctx context.Context, dir string, steps []postStep, first, total int) error {
+	for i, ps := range steps {
+		ins.step(first+i, total, fmt.Sprintf("%s (%s)", ps.step.Name, ps.component))
+		if err := ins.runStep(ctx, dir, ps.step); err != nil {
+			return fmt.Errorf("post-install %q for %s: %w", ps.step.Name, ps.component, err)
+		}
+	}
+	return nil
+}
+
+// runStep executes a single step with node_modules/.bin ahead of PATH.
+func (ins *Installer) runStep(ctx context.Context, dir string, s manifest.Step) error {
+	binDir := filepath.Join(dir, "node_modules", ".bin")
+	name := s.Run[0]
+	if !strings.ContainsRune(name, filepath.Separator) {
+		if local := filepath.Join(binDir, name); fileExists(local) {
+			name = local
+		}
+	}
+
+	// The command comes from whatever manifest the user chose to install
+	// from: a signature-verified remote one, but also an unsigned local
+	// --manifest file or overlay, or a bundle snapshot. Those are trusted
+	// like the packages they install, whose own scripts run too.
+	cmd := pm.Command(ctx, dir, name, s.Run[1:]...)
+	cmd.Env = append(os.Environ(), "PATH="+binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sc := bufio.NewScanner(pr)
+		for sc.Scan() {
+			line := strings.TrimRight(sc.Text(), "\r")
+			if line == "" {
+				continue
+			}
+			ins.Log.Printf("  %s", line)
+			if ins.OnLine != nil {
+				ins.OnLine(ins.Log.Scrub(line))
+			}
+		}
+		_, _ = io.Copy(io.Discard, pr) // keep draining if a line was too long
+	}()
+
+	err := cmd.Run()
+	_ = pw.Close()
+	<-done
+	return err
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
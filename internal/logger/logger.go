@@ -7,13 +7,22 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 )
 
+// redacted replaces secrets registered with Redact.
+const redacted = "***"
+
 // Logger writes to both stderr and a log file simultaneously.
 type Logger struct {
 	w    io.Writer
 	file *os.File
+
+	mu      sync.Mutex
+	secrets []string
+	redact  *strings.Replacer // nil until Redact is called
 }
 
 // New creates a logger that writes to stderr and to <platformDir>/.kb/logs/install-<ts>.log.
@@ -51,14 +60,56 @@ func (l *Logger) LogPath() string {
 	return l.file.Name()
 }
 
-// Write implements io.Writer — forwards to the underlying multi-writer.
+// Redact makes the logger replace every later occurrence of each secret
+// (e.g. a registry auth token) with "***".
+func (l *Logger) Redact(secrets ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, s := range secrets {
+		if s != "" {
+			l.secrets = append(l.secrets, s)
+		}
+	}
+	if len(l.secrets) == 0 {
+		return
+	}
+	pairs := make([]string, 0, 2*len(l.secrets))
+	for _, s := range l.secrets {
+		pairs = append(pairs, s, redacted)
+	}
+	l.redact = strings.NewReplacer(pairs...)
+}
+
+// Scrub returns s with every secret registered with Redact replaced, for
+// output that reaches the terminal without going through the logger.
+func (l *Logger) Scrub(s string) string {
+	l.mu.Lock()
+	r := l.redact
+	l.mu.Unlock()
+	if r == nil {
+		return s
+	}
+	return r.Replace(s)
+}
+
+// Write implements io.Writer — forwards to the underlying multi-writer,
+// redacting secrets registered with Redact.
 func (l *Logger) Write(p []byte) (n int, err error) {
-	return l.w.Write(p)
+	l.mu.Lock()
+	r := l.redact
+	l.mu.Unlock()
+	if r == nil {
+		return l.w.Write(p)
+	}
+	if _, err := io.WriteString(l.w, r.Replace(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
 }
 
 // Printf writes a formatted line to the log.
 func (l *Logger) Printf(format string, args ...any) {
-	_, _ = fmt.Fprintf(l.w, format+"\n", args...)
+	_, _ = fmt.Fprintf(l, format+"\n", args...)
 }
 
 // Close flushes and closes the log file.
@@ -85,6 +85,31 @@ func TestNewPrintfWritesToFile(t *testing.T) {
 	}
 }
 
+// TestRedactHidesSecrets verifies that secrets registered with Redact never
+// reach the log file.
+func TestRedactHidesSecrets(t *testing.T) {
+	dir := t.TempDir()
+
+	l, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	l.Redact("npm_s3cr3t", "")
+	l.Printf("GET https://npm.corp.example/ authorization=Bearer %s", "npm_s3cr3t")
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(l.LogPath())
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(data), "npm_s3cr3t") || !strings.Contains(string(data), "Bearer ***") {
+		t.Errorf("log file content %q, want the token redacted", string(data))
+	}
+}
+
 // TestNewDiscardLogPathEmpty verifies that NewDiscard returns "" for LogPath.
 func TestNewDiscardLogPathEmpty(t *testing.T) {
 	l := NewDiscard()
@@ -0,0 +1,124 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CacheEntry is the metadata stored next to a cached remote manifest.
+type CacheEntry struct {
+	FetchedAt    time.Time `json:"fetchedAt"`   // when the body was last downloaded
+	ValidatedAt  time.Time `json:"validatedAt"` // when the server last confirmed it (200 or 304)
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+}
+
+// DefaultCacheDir returns <user cache dir>/kb-create/manifests, or "" when
+// the platform has no user cache directory (caching is then disabled).
+func DefaultCacheDir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(base, "kb-create", "manifests")
+}
+
+// CacheInfo returns the metadata of the cached copy of url in dir.
+func CacheInfo(dir, url string) (*CacheEntry, error) {
+	c := newCache(dir).get(url)
+	if c == nil {
+		return nil, fmt.Errorf("no cached copy of %s", url)
+	}
+	return &c.entry, nil
+}
+
+// manifestCache stores one manifest per URL as <key>.json, <key>.sig and
+// <key>.meta.json. A nil *manifestCache (no dir configured) is a no-op.
+type manifestCache struct {
+	dir string
+}
+
+type cachedManifest struct {
+	data  []byte
+	sig   []byte // nil when fetched with verification disabled
+	entry CacheEntry
+}
+
+func newCache(dir string) *manifestCache {
+	if dir == "" {
+		return nil
+	}
+	return &manifestCache{dir: dir}
+}
+
+func (c *manifestCache) path(url, ext string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:8])+ext)
+}
+
+// get returns the cached copy of url, or nil if there is none.
+func (c *manifestCache) get(url string) *cachedManifest {
+	if c == nil {
+		return nil
+	}
+	// #nosec G304 -- paths are <cache dir>/<sha256 of url><ext>.
+	meta, err := os.ReadFile(c.path(url, ".meta.json"))
+	if err != nil {
+		return nil
+	}
+	var cm cachedManifest
+	if err := json.Unmarshal(meta, &cm.entry); err != nil || cm.entry.URL != url {
+		return nil
+	}
+	if cm.data, err = os.ReadFile(c.path(url, ".json")); err != nil {
+		return nil
+	}
+	if sig, err := os.ReadFile(c.path(url, SignatureSuffix)); err == nil {
+		cm.sig = sig
+	}
+	return &cm
+}
+
+// put stores a freshly downloaded manifest, replacing any previous copy.
+func (c *manifestCache) put(data, sig []byte, entry CacheEntry) error {
+	if c == nil {
+		return nil
+	}
+	if err := os.MkdirAll(c.dir, 0o750); err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.path(entry.URL, ".json"), data, 0o600); err != nil {
+		return err
+	}
+	sigPath := c.path(entry.URL, SignatureSuffix)
+	if sig != nil {
+		if err := os.WriteFile(sigPath, sig, 0o600); err != nil {
+			return err
+		}
+	} else if err := os.Remove(sigPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return c.writeMeta(entry)
+}
+
+// touch rewrites only the metadata, e.g. after a 304 revalidation.
+func (c *manifestCache) touch(entry CacheEntry) {
+	if c == nil {
+		return
+	}
+	_ = c.writeMeta(entry)
+}
+
+func (c *manifestCache) writeMeta(entry CacheEntry) error {
+	meta, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path(entry.URL, ".meta.json"), meta, 0o600)
+}
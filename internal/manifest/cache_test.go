@@ -0,0 +1,109 @@
+package manifest
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// etagServer serves a signed manifest with an ETag and answers matching
+// If-None-Match requests with 304. It counts full (200) manifest downloads.
+func etagServer(t *testing.T, data []byte, priv ed25519.PrivateKey, downloads *atomic.Int32) *httptest.Server {
+	t.Helper()
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, data))
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, SignatureSuffix) {
+			_, _ = w.Write([]byte(sig))
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		_, _ = w.Write(data)
+	}))
+}
+
+// TestLoadRemoteRevalidatesWithETag verifies that a second load sends a
+// conditional request and reuses the cached body on 304.
+func TestLoadRemoteRevalidatesWithETag(t *testing.T) {
+	pub, priv := testKey(t)
+	var downloads atomic.Int32
+	srv := etagServer(t, []byte(`{"version":"cached-1.0"}`), priv, &downloads)
+	defer srv.Close()
+
+	opts := LoadOptions{
+		RemoteURL:   srv.URL + "/manifest.json",
+		TrustedKeys: []ed25519.PublicKey{pub},
+		CacheDir:    t.TempDir(),
+	}
+	for i := 0; i < 2; i++ {
+		m, err := Load(opts)
+		if err != nil {
+			t.Fatalf("Load() #%d error = %v", i+1, err)
+		}
+		if m.Version != "cached-1.0" || m.Source.Kind != SourceRemote {
+			t.Errorf("Load() #%d = %q from %s", i+1, m.Version, m.Source)
+		}
+	}
+	if got := downloads.Load(); got != 1 {
+		t.Errorf("full downloads = %d, want 1 (second load should get 304)", got)
+	}
+
+	entry, err := CacheInfo(opts.CacheDir, opts.RemoteURL)
+	if err != nil {
+		t.Fatalf("CacheInfo() error = %v", err)
+	}
+	if entry.ETag != `"v1"` {
+		t.Errorf("ETag = %q, want %q", entry.ETag, `"v1"`)
+	}
+}
+
+// TestLoadRemoteOfflineUsesCache verifies that when the server is gone the
+// last good cached copy is used before the embedded manifest.
+func TestLoadRemoteOfflineUsesCache(t *testing.T) {
+	pub, priv := testKey(t)
+	var downloads atomic.Int32
+	srv := etagServer(t, []byte(`{"version":"cached-2.0"}`), priv, &downloads)
+
+	opts := LoadOptions{
+		RemoteURL:   srv.URL + "/manifest.json",
+		TrustedKeys: []ed25519.PublicKey{pub},
+		CacheDir:    t.TempDir(),
+		Timeout:     500 * time.Millisecond,
+	}
+	if _, err := Load(opts); err != nil {
+		t.Fatalf("initial Load() error = %v", err)
+	}
+	srv.Close()
+
+	var warnings []string
+	opts.OnWarn = func(msg string) { warnings = append(warnings, msg) }
+	m, err := Load(opts)
+	if err != nil {
+		t.Fatalf("offline Load() error = %v", err)
+	}
+	if m.Version != "cached-2.0" {
+		t.Errorf("Version = %q, want cached copy", m.Version)
+	}
+	if m.Source.Kind != SourceCache {
+		t.Errorf("Source.Kind = %q, want %q", m.Source.Kind, SourceCache)
+	}
+	if len(warnings) == 0 {
+		t.Error("expected a warning when falling back to the cache")
+	}
+}
+
+// TestLoadRemoteNoCacheDir verifies that caching is disabled without CacheDir.
+func TestLoadRemoteNoCacheDir(t *testing.T) {
+	if _, err := CacheInfo("", "https://example.com/manifest.json"); err == nil {
+		t.Error("CacheInfo() with empty dir should report no cached copy")
+	}
+}
@@ -0,0 +1,79 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeManifest writes JSON to dir/name and returns the path.
+func writeManifest(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestLoadChannelFollowsMap verifies that a non-default channel is loaded
+// from the location listed in the base manifest's channel map.
+func TestLoadChannelFollowsMap(t *testing.T) {
+	dir := t.TempDir()
+	beta := writeManifest(t, dir, "beta.json", `{"version":"2.0.0-beta.1","channel":"beta"}`)
+	base := writeManifest(t, dir, "stable.json", `{"version":"1.0.0","channels":{"beta":"`+beta+`"}}`)
+
+	m, err := Load(LoadOptions{LocalOverride: base, Channel: "beta"})
+	if err != nil {
+		t.Fatalf("Load(channel=beta) error = %v", err)
+	}
+	if m.Version != "2.0.0-beta.1" || m.ChannelName() != "beta" {
+		t.Errorf("got version %q channel %q, want beta manifest", m.Version, m.ChannelName())
+	}
+	if m.Source.Location != beta {
+		t.Errorf("Source.Location = %q, want %q", m.Source.Location, beta)
+	}
+	if m.BaseSource.Location != base {
+		t.Errorf("BaseSource.Location = %q, want %q", m.BaseSource.Location, base)
+	}
+}
+
+// TestLoadChannelSameAsBase verifies that requesting the base manifest's own
+// channel does not switch.
+func TestLoadChannelSameAsBase(t *testing.T) {
+	dir := t.TempDir()
+	base := writeManifest(t, dir, "stable.json", `{"version":"1.0.0"}`)
+
+	m, err := Load(LoadOptions{LocalOverride: base, Channel: DefaultChannel})
+	if err != nil {
+		t.Fatalf("Load(channel=stable) error = %v", err)
+	}
+	if m.Version != "1.0.0" {
+		t.Errorf("Version = %q, want 1.0.0", m.Version)
+	}
+}
+
+// TestLoadChannelUnknown verifies that an undefined channel is an error
+// listing the available ones.
+func TestLoadChannelUnknown(t *testing.T) {
+	dir := t.TempDir()
+	base := writeManifest(t, dir, "stable.json", `{"version":"1.0.0","channels":{"beta":"x.json"}}`)
+
+	_, err := Load(LoadOptions{LocalOverride: base, Channel: "nightly"})
+	if err == nil || !strings.Contains(err.Error(), "beta") {
+		t.Errorf("Load(channel=nightly) error = %v, want unknown channel listing beta", err)
+	}
+}
+
+// TestLoadChannelMismatch verifies that a channel manifest declaring a
+// different channel is rejected.
+func TestLoadChannelMismatch(t *testing.T) {
+	dir := t.TempDir()
+	wrong := writeManifest(t, dir, "nightly.json", `{"version":"3.0.0"}`)
+	base := writeManifest(t, dir, "stable.json", `{"version":"1.0.0","channels":{"nightly":"`+wrong+`"}}`)
+
+	if _, err := Load(LoadOptions{LocalOverride: base, Channel: "nightly"}); err == nil {
+		t.Error("Load() should reject a channel manifest declaring a different channel")
+	}
+}
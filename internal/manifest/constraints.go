@@ -0,0 +1,88 @@
+package manifest
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/kb-labs/create/internal/semver"
+)
+
+// Constraints restricts where a component, or the whole manifest, can be
+// installed. Empty fields impose no restriction.
+type Constraints struct {
+	Node string   `json:"node,omitempty"` // semver range for `node --version`, e.g. ">=20"
+	OS   []string `json:"os,omitempty"`   // GOOS values, e.g. ["darwin", "linux"]
+	Arch []string `json:"arch,omitempty"` // GOARCH values, e.g. ["amd64", "arm64"]
+}
+
+// IsZero reports whether c imposes no restriction.
+func (c Constraints) IsZero() bool {
+	return c.Node == "" && len(c.OS) == 0 && len(c.Arch) == 0
+}
+
+func (c Constraints) String() string {
+	var parts []string
+	if c.Node != "" {
+		parts = append(parts, "node "+c.Node)
+	}
+	if len(c.OS) > 0 {
+		parts = append(parts, "os "+strings.Join(c.OS, "/"))
+	}
+	if len(c.Arch) > 0 {
+		parts = append(parts, "arch "+strings.Join(c.Arch, "/"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Host describes the machine the platform is being installed on.
+type Host struct {
+	OS   string // runtime.GOOS
+	Arch string // runtime.GOARCH
+	Node string // node version without the leading "v"; empty if node is missing
+}
+
+// CurrentHost returns the running OS/arch with the given node version.
+func CurrentHost(nodeVersion string) Host {
+	return Host{OS: runtime.GOOS, Arch: runtime.GOARCH, Node: strings.TrimPrefix(nodeVersion, "v")}
+}
+
+// Check returns nil if h satisfies c, otherwise an error explaining why not.
+func (c Constraints) Check(h Host) error {
+	if len(c.OS) > 0 && !slices.Contains(c.OS, h.OS) {
+		return fmt.Errorf("not supported on %s (supported: %s)", h.OS, strings.Join(c.OS, ", "))
+	}
+	if len(c.Arch) > 0 && !slices.Contains(c.Arch, h.Arch) {
+		return fmt.Errorf("not supported on %s (supported: %s)", h.Arch, strings.Join(c.Arch, ", "))
+	}
+	if c.Node != "" {
+		if h.Node == "" {
+			return fmt.Errorf("requires node %s (node not found)", c.Node)
+		}
+		ok, err := semver.Satisfies(h.Node, c.Node)
+		if err != nil {
+			return fmt.Errorf("check node %s: %w", c.Node, err)
+		}
+		if !ok {
+			return fmt.Errorf("requires node %s (found %s)", c.Node, h.Node)
+		}
+	}
+	return nil
+}
+
+// CheckComponent returns nil if component id can be installed on h.
+// Manifest-level constraints apply to every component.
+func (m *Manifest) CheckComponent(id string, h Host) error {
+	if err := m.Constraints.Check(h); err != nil {
+		return fmt.Errorf("platform %w", err)
+	}
+	c, ok := m.Component(id)
+	if !ok {
+		return nil
+	}
+	if err := c.Constraints.Check(h); err != nil {
+		return fmt.Errorf("%s %w", id, err)
+	}
+	return nil
+}
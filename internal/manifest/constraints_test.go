@@ -0,0 +1,69 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestConstraintsCheck verifies OS, arch and node range matching.
+func TestConstraintsCheck(t *testing.T) {
+	host := Host{OS: "linux", Arch: "arm64", Node: "20.11.1"}
+	tests := []struct {
+		name string
+		c    Constraints
+		want string // substring of the error; empty means satisfied
+	}{
+		{"zero", Constraints{}, ""},
+		{"os ok", Constraints{OS: []string{"darwin", "linux"}}, ""},
+		{"os mismatch", Constraints{OS: []string{"darwin"}}, "not supported on linux"},
+		{"arch mismatch", Constraints{Arch: []string{"amd64"}}, "not supported on arm64"},
+		{"node ok", Constraints{Node: ">=20"}, ""},
+		{"node too old", Constraints{Node: "^22"}, "requires node ^22 (found 20.11.1)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.c.Check(host)
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("Check() error = %v, want nil", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("Check() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestConstraintsCheckMissingNode verifies that a node range fails when
+// node isn't installed.
+func TestConstraintsCheckMissingNode(t *testing.T) {
+	err := Constraints{Node: ">=20"}.Check(Host{OS: "linux", Arch: "amd64"})
+	if err == nil || !strings.Contains(err.Error(), "node not found") {
+		t.Errorf("Check() error = %v, want node not found", err)
+	}
+}
+
+// TestCheckComponentAppliesManifestConstraints verifies that manifest-level
+// constraints apply to every component.
+func TestCheckComponentAppliesManifestConstraints(t *testing.T) {
+	m := &Manifest{
+		Constraints: Constraints{OS: []string{"linux"}},
+		Plugins:     []Component{{ID: "mind", Pkg: "@kb-labs/mind"}},
+	}
+	err := m.CheckComponent("mind", Host{OS: "windows", Arch: "amd64", Node: "20.0.0"})
+	if err == nil || !strings.HasPrefix(err.Error(), "platform ") {
+		t.Errorf("CheckComponent() error = %v, want platform constraint", err)
+	}
+}
+
+// TestValidateNodeRange verifies that malformed node ranges are reported.
+func TestValidateNodeRange(t *testing.T) {
+	m := &Manifest{
+		Version: "1.0.0",
+		Core:    []Package{{Name: "@kb-labs/cli-bin"}},
+		Plugins: []Component{{ID: "mind", Pkg: "@kb-labs/mind", Constraints: Constraints{Node: ">=banana"}}},
+	}
+	problems := Validate(m)
+	if len(problems) != 1 || problems[0].Path != "$.plugins[0].constraints.node" {
+		t.Errorf("Validate() = %v, want one problem at $.plugins[0].constraints.node", problems)
+	}
+}
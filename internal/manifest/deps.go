@@ -0,0 +1,100 @@
+package manifest
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Component returns the service or plugin with the given ID.
+func (m *Manifest) Component(id string) (Component, bool) {
+	for _, c := range m.Services {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	for _, c := range m.Plugins {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return Component{}, false
+}
+
+// Replacement returns the component that replaces the deprecated component
+// id, if it names one.
+func (m *Manifest) Replacement(id string) (Component, bool) {
+	c, ok := m.Component(id)
+	if !ok || !c.IsDeprecated() || c.ReplacedBy == "" {
+		return Component{}, false
+	}
+	return m.Component(c.ReplacedBy)
+}
+
+// IsService reports whether id names a component in Services.
+func (m *Manifest) IsService(id string) bool {
+	return slices.ContainsFunc(m.Services, func(c Component) bool { return c.ID == id })
+}
+
+// Resolve expands ids with every transitively required component and checks
+// the result for conflicts. The returned slice keeps the original order and
+// appends pulled-in requirements after the component that needs them.
+func (m *Manifest) Resolve(ids []string) ([]string, error) {
+	var out []string
+	seen := make(map[string]bool)
+
+	var visit func(id, requiredBy string) error
+	visit = func(id, requiredBy string) error {
+		if seen[id] {
+			return nil
+		}
+		c, ok := m.Component(id)
+		if !ok {
+			if requiredBy != "" {
+				return fmt.Errorf("component %q requires unknown component %q", requiredBy, id)
+			}
+			// Unknown top-level IDs are ignored, same as selection lookups.
+			return nil
+		}
+		seen[id] = true
+		out = append(out, id)
+		for _, dep := range c.Requires {
+			if err := visit(dep, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, id := range ids {
+		if err := visit(id, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, a := range out {
+		for _, b := range out[i+1:] {
+			if m.Conflicts(a, b) {
+				return nil, fmt.Errorf("components %q and %q conflict and cannot be installed together", a, b)
+			}
+		}
+	}
+	return out, nil
+}
+
+// Conflicts reports whether components a and b declare a conflict in
+// either direction.
+func (m *Manifest) Conflicts(a, b string) bool {
+	ca, okA := m.Component(a)
+	cb, okB := m.Component(b)
+	return (okA && slices.Contains(ca.Conflicts, b)) || (okB && slices.Contains(cb.Conflicts, a))
+}
+
+// RequiredBy returns the IDs in selected that directly require id.
+func (m *Manifest) RequiredBy(id string, selected []string) []string {
+	var out []string
+	for _, s := range selected {
+		if c, ok := m.Component(s); ok && slices.Contains(c.Requires, id) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
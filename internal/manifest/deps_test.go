@@ -0,0 +1,89 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+)
+
+func depsManifest() *Manifest {
+	return &Manifest{
+		Version: "1.0.0",
+		Services: []Component{
+			{ID: "rest", Pkg: "@kb-labs/rest-api"},
+			{ID: "workflow", Pkg: "@kb-labs/workflow-runtime", Requires: []string{"rest"}},
+			{ID: "studio", Pkg: "@kb-labs/studio"},
+		},
+		Plugins: []Component{
+			{ID: "agents", Pkg: "@kb-labs/agents", Requires: []string{"workflow"}},
+			{ID: "legacy", Pkg: "@kb-labs/legacy", Conflicts: []string{"agents"}},
+		},
+	}
+}
+
+// TestResolvePullsTransitiveRequirements verifies that requirements are
+// followed transitively and appended after the requesting component.
+func TestResolvePullsTransitiveRequirements(t *testing.T) {
+	got, err := depsManifest().Resolve([]string{"studio", "agents"})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	want := []string{"studio", "agents", "workflow", "rest"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Resolve() = %v, want %v", got, want)
+	}
+}
+
+// TestResolveRejectsConflicts verifies that conflicts are detected in either
+// direction, including against pulled-in requirements.
+func TestResolveRejectsConflicts(t *testing.T) {
+	if _, err := depsManifest().Resolve([]string{"agents", "legacy"}); err == nil {
+		t.Error("Resolve(agents, legacy) should fail with a conflict")
+	}
+	if _, err := depsManifest().Resolve([]string{"legacy", "agents"}); err == nil {
+		t.Error("Resolve(legacy, agents) should fail with a conflict")
+	}
+}
+
+// TestRequiredBy verifies direct reverse-dependency lookup.
+func TestRequiredBy(t *testing.T) {
+	got := depsManifest().RequiredBy("workflow", []string{"agents", "studio"})
+	if len(got) != 1 || got[0] != "agents" {
+		t.Errorf("RequiredBy(workflow) = %v, want [agents]", got)
+	}
+}
+
+// TestValidateUnknownReference verifies that requires/conflicts must point at
+// known components.
+func TestValidateUnknownReference(t *testing.T) {
+	m := depsManifest()
+	m.Plugins[0].Requires = []string{"nope"}
+	m.Plugins[1].Conflicts = []string{"legacy"}
+
+	got := make(map[string]bool)
+	for _, p := range Validate(m) {
+		got[p.Path] = true
+	}
+	if !got["$.plugins[0].requires[0]"] || !got["$.plugins[1].conflicts[0]"] || len(got) != 2 {
+		t.Errorf("Validate() problems = %v", got)
+	}
+}
+
+// TestReplacement verifies that only deprecated components with a known
+// replacement resolve to one.
+func TestReplacement(t *testing.T) {
+	m := depsManifest()
+	m.Plugins = append(m.Plugins,
+		Component{ID: "commit", Pkg: "@kb-labs/commit"},
+		Component{ID: "commit-cli", Pkg: "@kb-labs/commit-cli", Deprecated: "renamed", ReplacedBy: "commit"},
+	)
+
+	if c, ok := m.Replacement("commit-cli"); !ok || c.ID != "commit" {
+		t.Errorf("Replacement(commit-cli) = %v, %v; want commit", c.ID, ok)
+	}
+	m.Plugins[1].Deprecated = "no successor"
+	for _, id := range []string{"commit", "legacy", "missing"} {
+		if c, ok := m.Replacement(id); ok {
+			t.Errorf("Replacement(%s) = %s, want none", id, c.ID)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package manifest
+
+import (
+	"fmt"
+
+	"github.com/kb-labs/create/internal/semver"
+)
+
+// InstallerVersionError is returned when a manifest declares a
+// minInstallerVersion newer than the running kb-create.
+type InstallerVersionError struct {
+	Required string // the manifest's minInstallerVersion
+	Current  string // the running kb-create version
+}
+
+func (e *InstallerVersionError) Error() string {
+	return fmt.Sprintf("manifest requires kb-create %s or newer, this is %s", e.Required, e.Current)
+}
+
+// CheckInstallerVersion returns an *InstallerVersionError if current is
+// older than required. An empty required, or a current of "" or "dev"
+// (a local build), always passes.
+func CheckInstallerVersion(required, current string) error {
+	if required == "" || current == "" || current == "dev" {
+		return nil
+	}
+	req, err := semver.Parse(required)
+	if err != nil {
+		return fmt.Errorf("manifest minInstallerVersion: %w", err)
+	}
+	cur, err := semver.Parse(current)
+	if err != nil {
+		// An unparsable build version can't be compared; don't block on it.
+		return nil
+	}
+	if semver.Compare(cur, req) < 0 {
+		return &InstallerVersionError{Required: required, Current: current}
+	}
+	return nil
+}
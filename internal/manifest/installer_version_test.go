@@ -0,0 +1,60 @@
+package manifest
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestCheckInstallerVersion verifies the version comparison and the cases
+// that always pass.
+func TestCheckInstallerVersion(t *testing.T) {
+	tests := []struct {
+		required, current string
+		wantErr           bool
+	}{
+		{"", "1.0.0", false},
+		{"1.4.0", "dev", false},
+		{"1.4.0", "", false},
+		{"1.4.0", "1.4.0", false},
+		{"1.4.0", "v1.5.2", false},
+		{"1.4.0", "1.3.9", true},
+		{"v2", "1.9.0", true},
+		{"1.4.0", "1.4.0-beta.1", true},
+	}
+	for _, tt := range tests {
+		err := CheckInstallerVersion(tt.required, tt.current)
+		var verr *InstallerVersionError
+		if got := errors.As(err, &verr); got != tt.wantErr {
+			t.Errorf("CheckInstallerVersion(%q, %q) = %v, want error %v", tt.required, tt.current, err, tt.wantErr)
+		}
+	}
+}
+
+// TestLoadRefusesNewerManifestBeforeValidation verifies that a manifest for
+// a newer installer is refused with InstallerVersionError even when the rest
+// of it would not validate under this binary's schema.
+func TestLoadRefusesNewerManifestBeforeValidation(t *testing.T) {
+	path := writeManifest(t, t.TempDir(), "next.json", `{"minInstallerVersion": "9.0.0", "core": [{"name": ""}]}`)
+
+	_, err := Load(LoadOptions{LocalOverride: path, InstallerVersion: "1.2.0"})
+	var verr *InstallerVersionError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Load() error = %v, want *InstallerVersionError", err)
+	}
+	if verr.Required != "9.0.0" || verr.Current != "1.2.0" {
+		t.Errorf("error = %+v, want Required 9.0.0, Current 1.2.0", verr)
+	}
+}
+
+// TestLoadRemoteTooNewIsFatal verifies that a remote manifest requiring a
+// newer installer does not fall back to the embedded manifest.
+func TestLoadRemoteTooNewIsFatal(t *testing.T) {
+	srv := signedServer([]byte(`{"version": "2.0.0", "minInstallerVersion": "9.0.0", "core": []}`), nil)
+	defer srv.Close()
+
+	_, err := Load(LoadOptions{RemoteURL: srv.URL + "/manifest.json", Insecure: true, InstallerVersion: "1.2.0"})
+	var verr *InstallerVersionError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Load() error = %v, want *InstallerVersionError", err)
+	}
+}
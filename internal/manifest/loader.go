@@ -4,11 +4,15 @@ import (
 	_ "embed"
 
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -24,18 +28,87 @@ type LoadOptions struct {
 	LocalOverride string
 	// Timeout for remote fetch. Default 5s.
 	Timeout time.Duration
+	// TrustedKeys are accepted for remote signatures in addition to the keys
+	// embedded in the binary.
+	TrustedKeys []ed25519.PublicKey
+	// Insecure skips signature verification of remote manifests.
+	Insecure bool
+	// CacheDir, if set, keeps the last good copy of each remote manifest for
+	// conditional revalidation and offline fallback. See DefaultCacheDir.
+	CacheDir string
+	// InstallerVersion is the running kb-create version. Manifests whose
+	// minInstallerVersion is newer are refused with *InstallerVersionError.
+	// Empty or "dev" skips the check.
+	InstallerVersion string
+	// Channel, if set and different from the loaded manifest's own channel,
+	// switches to that release channel via the manifest's Channels map.
+	Channel string
+	// Overlays lists files or URLs of partial manifests merged, in order, on
+	// top of the loaded manifest. See Overlay.
+	Overlays []string
+	// OnWarn, if set, is called when a configured source is skipped and Load
+	// falls back to the next one, so callers can surface the reason.
+	OnWarn func(msg string)
+}
+
+func (o LoadOptions) warn(format string, args ...any) {
+	if o.OnWarn != nil {
+		o.OnWarn(fmt.Sprintf(format, args...))
+	}
 }
 
 // Load returns the manifest using the fallback chain:
 //
-//	Remote URL → Local override file → Embedded JSON
+//	Remote URL → Cached copy of remote → Local override file → Embedded JSON
+//
+// and then, if opts.Channel asks for a different release channel, follows
+// the Channels map of that manifest. Finally opts.Overlays are merged on top.
+// The returned manifest's Source and Overlays record where each layer was
+// actually loaded from.
 func Load(opts LoadOptions) (*Manifest, error) {
+	m, err := loadChain(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Channel != "" && opts.Channel != m.ChannelName() {
+		base := m.Source
+		if m, err = loadChannel(m, opts); err != nil {
+			return nil, err
+		}
+		m.BaseSource = base
+	}
+	m.TrustedKeys = opts.TrustedKeys
+	return applyOverlays(m, opts)
+}
+
+// IsRemote reports whether a manifest location is an http(s) URL rather
+// than a file path.
+func IsRemote(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+func loadChain(opts LoadOptions) (*Manifest, error) {
 	if opts.RemoteURL != "" {
-		m, err := loadRemote(opts.RemoteURL, opts.Timeout)
+		m, err := loadRemote(opts)
 		if err == nil {
+			m.Source = Source{Kind: SourceRemote, Location: opts.RemoteURL}
 			return m, nil
 		}
-		// non-fatal: fall through to next source
+		// A tampered or unsigned manifest is a hard failure, not an outage,
+		// and so is one this binary is too old to read.
+		var verr *InstallerVersionError
+		if errors.Is(err, ErrSignature) || errors.As(err, &verr) {
+			return nil, err
+		}
+		// non-fatal: fall through to the last good cached copy, then to the
+		// next source, but never silently.
+		if cm, entry, cacheErr := loadCached(opts); cacheErr == nil {
+			opts.warn("remote manifest unavailable, using cached copy from %s: %v",
+				entry.FetchedAt.Local().Format("2006-01-02 15:04"), err)
+			cm.Source = Source{Kind: SourceCache, Location: opts.RemoteURL}
+			return cm, nil
+		}
+		opts.warn("remote manifest unavailable, falling back: %v", err)
 	}
 
 	if opts.LocalOverride != "" {
@@ -43,15 +116,26 @@ func Load(opts LoadOptions) (*Manifest, error) {
 		data, readErr := os.ReadFile(opts.LocalOverride)
 		if readErr == nil {
 			// File exists — parse errors are always fatal (no silent fallback).
-			return loadBytes(data)
+			m, err := opts.parse(data)
+			if err != nil {
+				return nil, err
+			}
+			m.Source = Source{Kind: SourceOverride, Location: opts.LocalOverride}
+			return m, nil
 		}
 		if !os.IsNotExist(readErr) {
 			return nil, fmt.Errorf("read override %s: %w", opts.LocalOverride, readErr)
 		}
 		// File not found — fall through to embedded.
+		opts.warn("manifest override %s not found, using embedded manifest", opts.LocalOverride)
 	}
 
-	return loadBytes(embeddedManifest)
+	m, err := opts.parse(embeddedManifest)
+	if err != nil {
+		return nil, err
+	}
+	m.Source = Source{Kind: SourceEmbedded}
+	return m, nil
 }
 
 // LoadDefault loads the embedded manifest with no remote/local overrides.
@@ -59,16 +143,172 @@ func LoadDefault() (*Manifest, error) {
 	return Load(LoadOptions{})
 }
 
-func loadRemote(url string, timeout time.Duration) (*Manifest, error) {
+// loadChannel switches from base to the manifest base.Channels lists for
+// opts.Channel. Unlike the base chain there is no fallback: silently
+// installing a different channel is worse than stopping.
+func loadChannel(base *Manifest, opts LoadOptions) (*Manifest, error) {
+	loc, ok := base.Channels[opts.Channel]
+	if !ok {
+		names := make([]string, 0, len(base.Channels))
+		for name := range base.Channels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("channel %q is not defined by manifest %s (available: %s)",
+			opts.Channel, base.Source, strings.Join(append([]string{base.ChannelName()}, names...), ", "))
+	}
+
+	var (
+		m   *Manifest
+		src Source
+		err error
+	)
+	if IsRemote(loc) {
+		opts.RemoteURL = loc
+		m, err = loadRemote(opts)
+		src = Source{Kind: SourceRemote, Location: loc}
+	} else {
+		var data []byte
+		// #nosec G304 -- loc comes from the channel map of a trusted manifest.
+		if data, err = os.ReadFile(loc); err == nil {
+			m, err = opts.parse(data)
+		}
+		src = Source{Kind: SourceOverride, Location: loc}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load channel %s: %w", opts.Channel, err)
+	}
+	if m.ChannelName() != opts.Channel {
+		return nil, fmt.Errorf("manifest at %s declares channel %q, want %q", loc, m.ChannelName(), opts.Channel)
+	}
+	m.Source = src
+	return m, nil
+}
+
+// loadRemote fetches the manifest and, unless opts.Insecure is set, its
+// detached signature at <url>.sig, verifying it before parsing. When
+// opts.CacheDir is set the request is conditional on the cached copy's
+// ETag/Last-Modified, and a 304 reuses the cached bytes.
+func loadRemote(opts LoadOptions) (*Manifest, error) {
+	timeout := opts.Timeout
 	if timeout == 0 {
 		timeout = 5 * time.Second
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
+
+	cache := newCache(opts.CacheDir)
+	cached := cache.get(opts.RemoteURL)
+	// A cached copy without a signature can't be reused by a verifying load,
+	// so don't ask the server to confirm it.
+	if cached != nil && cached.sig == nil && !opts.Insecure {
+		cached = nil
+	}
+
+	hdr := http.Header{}
+	if cached != nil {
+		if cached.entry.ETag != "" {
+			hdr.Set("If-None-Match", cached.entry.ETag)
+		}
+		if cached.entry.LastModified != "" {
+			hdr.Set("If-Modified-Since", cached.entry.LastModified)
+		}
+	}
+	res, err := fetch(ctx, opts.RemoteURL, hdr)
+	if err != nil {
+		return nil, err
+	}
+	if res.status == http.StatusNotModified && cached != nil {
+		cached.entry.ValidatedAt = time.Now().UTC()
+		cache.touch(cached.entry)
+		return verifyAndParse(cached.data, cached.sig, opts)
+	}
+	if res.status != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: status %d", opts.RemoteURL, res.status)
+	}
+
+	var sig []byte
+	if !opts.Insecure {
+		sigURL := opts.RemoteURL + SignatureSuffix
+		sigRes, err := fetch(ctx, sigURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		if sigRes.status == http.StatusNotFound {
+			return nil, fmt.Errorf("%w: %s is unsigned (no %s); pass --insecure-manifest to accept it", ErrSignature, opts.RemoteURL, sigURL)
+		}
+		if sigRes.status != http.StatusOK {
+			return nil, fmt.Errorf("fetch %s: status %d", sigURL, sigRes.status)
+		}
+		sig = sigRes.body
+	}
+
+	m, err := verifyAndParse(res.body, sig, opts)
+	if err != nil {
+		return nil, err
+	}
+	// Only a verified, valid manifest becomes the "last good" cached copy.
+	now := time.Now().UTC()
+	if err := cache.put(res.body, sig, CacheEntry{
+		URL:          opts.RemoteURL,
+		ETag:         res.header.Get("ETag"),
+		LastModified: res.header.Get("Last-Modified"),
+		FetchedAt:    now,
+		ValidatedAt:  now,
+	}); err != nil {
+		opts.warn("could not cache manifest: %v", err)
+	}
+	return m, nil
+}
+
+// loadCached returns the last good cached copy of opts.RemoteURL, verified
+// the same way a fresh download would be.
+func loadCached(opts LoadOptions) (*Manifest, *CacheEntry, error) {
+	cached := newCache(opts.CacheDir).get(opts.RemoteURL)
+	if cached == nil {
+		return nil, nil, fmt.Errorf("no cached copy of %s", opts.RemoteURL)
+	}
+	m, err := verifyAndParse(cached.data, cached.sig, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, &cached.entry, nil
+}
+
+// verifyAndParse checks sig over data against the embedded and configured
+// keys (unless opts.Insecure) and parses the manifest.
+func verifyAndParse(data, sig []byte, opts LoadOptions) (*Manifest, error) {
+	if opts.Insecure {
+		opts.warn("signature verification disabled for %s", opts.RemoteURL)
+		return opts.parse(data)
+	}
+	keys, err := EmbeddedKeys()
+	if err != nil {
+		return nil, err
+	}
+	keys = append(keys, opts.TrustedKeys...)
+	if err := Verify(data, sig, keys); err != nil {
+		return nil, fmt.Errorf("%s: %w", opts.RemoteURL, err)
+	}
+	return opts.parse(data)
+}
+
+// fetchResult is the outcome of a single GET. body is only read for 200.
+type fetchResult struct {
+	header http.Header
+	body   []byte
+	status int
+}
+
+// fetch GETs url with the extra request headers hdr.
+func fetch(ctx context.Context, url string, hdr http.Header) (*fetchResult, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
 	if err != nil {
 		return nil, fmt.Errorf("fetch %s: %w", url, err)
 	}
+	for k, v := range hdr {
+		req.Header[k] = v
+	}
 	client := &http.Client{}
 	// #nosec G704 -- URL is explicitly provided as a manifest source override.
 	resp, err := client.Do(req)
@@ -76,20 +316,41 @@ func loadRemote(url string, timeout time.Duration) (*Manifest, error) {
 		return nil, fmt.Errorf("fetch %s: %w", url, err)
 	}
 	defer func() { _ = resp.Body.Close() }()
+	res := &fetchResult{header: resp.Header, status: resp.StatusCode}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("fetch %s: status %d", url, resp.StatusCode)
+		return res, nil
 	}
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
+	if res.body, err = io.ReadAll(resp.Body); err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", url, err)
+	}
+	return res, nil
+}
+
+// parse checks minInstallerVersion before Parse, so a manifest written for a
+// newer schema is refused with a clear error instead of half-understood
+// validation problems.
+func (o LoadOptions) parse(data []byte) (*Manifest, error) {
+	var head struct {
+		MinInstallerVersion string `json:"minInstallerVersion"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	if err := CheckInstallerVersion(head.MinInstallerVersion, o.InstallerVersion); err != nil {
 		return nil, err
 	}
-	return loadBytes(data)
+	return Parse(data)
 }
 
-func loadBytes(data []byte) (*Manifest, error) {
+// Parse decodes manifest JSON and validates it. Validation failures are
+// returned as a *ValidationError listing every problem.
+func Parse(data []byte) (*Manifest, error) {
 	var m Manifest
 	if err := json.Unmarshal(data, &m); err != nil {
 		return nil, fmt.Errorf("parse manifest: %w", err)
 	}
+	if problems := Validate(&m); len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
 	return &m, nil
 }
@@ -1,11 +1,17 @@
 package manifest
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -48,6 +54,25 @@ func TestCorePackageNames(t *testing.T) {
 	}
 }
 
+// TestSpec verifies that version ranges are appended as name@range and that
+// unpinned packages resolve to the bare name.
+func TestSpec(t *testing.T) {
+	tests := []struct {
+		got  string
+		want string
+	}{
+		{Package{Name: "@kb-labs/sdk"}.Spec(), "@kb-labs/sdk"},
+		{Package{Name: "@kb-labs/sdk", Version: "^1.2.0"}.Spec(), "@kb-labs/sdk@^1.2.0"},
+		{Component{Pkg: "@kb-labs/mind"}.Spec(), "@kb-labs/mind"},
+		{Component{Pkg: "@kb-labs/mind", Version: "1.3.1"}.Spec(), "@kb-labs/mind@1.3.1"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("Spec() = %q, want %q", tt.got, tt.want)
+		}
+	}
+}
+
 // TestLoadLocalOverride verifies that a local JSON file is used when provided.
 func TestLoadLocalOverride(t *testing.T) {
 	custom := Manifest{
@@ -76,13 +101,15 @@ func TestLoadRemoteOK(t *testing.T) {
 	custom := Manifest{Version: "remote-1.0"}
 	data, _ := json.Marshal(custom)
 
-	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write(data)
-	}))
+	pub, priv := testKey(t)
+	srv := signedServer(data, ed25519.Sign(priv, data))
 	defer srv.Close()
 
-	m, err := Load(LoadOptions{RemoteURL: srv.URL, Timeout: 2 * time.Second})
+	m, err := Load(LoadOptions{
+		RemoteURL:   srv.URL + "/manifest.json",
+		Timeout:     2 * time.Second,
+		TrustedKeys: []ed25519.PublicKey{pub},
+	})
 	if err != nil {
 		t.Fatalf("Load(RemoteURL) error = %v", err)
 	}
@@ -109,6 +136,159 @@ func TestLoadRemoteFallsBackToEmbedded(t *testing.T) {
 	}
 }
 
+// TestLoadRecordsSource verifies that Load reports which link of the fallback
+// chain produced the manifest.
+func TestLoadRecordsSource(t *testing.T) {
+	data := []byte(`{"version":"remote"}`)
+	pub, priv := testKey(t)
+	srv := signedServer(data, ed25519.Sign(priv, data))
+	defer srv.Close()
+	url := srv.URL + "/manifest.json"
+
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	if err := os.WriteFile(path, []byte(`{"version":"local"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts LoadOptions
+		want Source
+	}{
+		{"embedded", LoadOptions{}, Source{Kind: SourceEmbedded}},
+		{"override", LoadOptions{LocalOverride: path}, Source{Kind: SourceOverride, Location: path}},
+		{"remote", LoadOptions{RemoteURL: url, TrustedKeys: []ed25519.PublicKey{pub}}, Source{Kind: SourceRemote, Location: url}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Load(tt.opts)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if m.Source != tt.want {
+				t.Errorf("Source = %+v, want %+v", m.Source, tt.want)
+			}
+		})
+	}
+}
+
+// TestLoadRemoteFailureWarns verifies that falling back from a failed remote
+// fetch is reported through OnWarn instead of happening silently.
+func TestLoadRemoteFailureWarns(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	var warnings []string
+	m, err := Load(LoadOptions{
+		RemoteURL: srv.URL,
+		Timeout:   2 * time.Second,
+		OnWarn:    func(msg string) { warnings = append(warnings, msg) },
+	})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if m.Source.Kind != SourceEmbedded {
+		t.Errorf("Source.Kind = %q, want %q", m.Source.Kind, SourceEmbedded)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "status 500") {
+		t.Errorf("warnings = %v, want one mentioning status 500", warnings)
+	}
+}
+
+// TestLoadRemoteUnsignedRejected verifies that a remote manifest without a
+// detached signature is rejected instead of falling back.
+func TestLoadRemoteUnsignedRejected(t *testing.T) {
+	srv := signedServer([]byte(`{"version":"remote"}`), nil)
+	defer srv.Close()
+
+	pub, _ := testKey(t)
+	_, err := Load(LoadOptions{RemoteURL: srv.URL + "/manifest.json", TrustedKeys: []ed25519.PublicKey{pub}})
+	if !errors.Is(err, ErrSignature) {
+		t.Fatalf("Load() error = %v, want ErrSignature", err)
+	}
+}
+
+// TestLoadRemoteBadSignatureRejected verifies that a signature from an
+// untrusted key (or over different bytes) is rejected.
+func TestLoadRemoteBadSignatureRejected(t *testing.T) {
+	data := []byte(`{"version":"remote"}`)
+	pub, _ := testKey(t)
+	_, otherPriv := testKey(t)
+	srv := signedServer(data, ed25519.Sign(otherPriv, data))
+	defer srv.Close()
+
+	_, err := Load(LoadOptions{RemoteURL: srv.URL + "/manifest.json", TrustedKeys: []ed25519.PublicKey{pub}})
+	if !errors.Is(err, ErrSignature) {
+		t.Fatalf("Load() error = %v, want ErrSignature", err)
+	}
+}
+
+// TestLoadRemoteInsecureSkipsVerification verifies that Insecure accepts an
+// unsigned remote manifest and warns about it.
+func TestLoadRemoteInsecureSkipsVerification(t *testing.T) {
+	srv := signedServer([]byte(`{"version":"unsigned"}`), nil)
+	defer srv.Close()
+
+	var warnings []string
+	m, err := Load(LoadOptions{
+		RemoteURL: srv.URL + "/manifest.json",
+		Insecure:  true,
+		OnWarn:    func(msg string) { warnings = append(warnings, msg) },
+	})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if m.Version != "unsigned" {
+		t.Errorf("Version = %q, want %q", m.Version, "unsigned")
+	}
+	if len(warnings) == 0 {
+		t.Error("expected a warning when signature verification is disabled")
+	}
+}
+
+// TestParsePublicKey verifies base64 decoding and length checking of keys.
+func TestParsePublicKey(t *testing.T) {
+	pub, _ := testKey(t)
+	got, err := ParsePublicKey(base64.StdEncoding.EncodeToString(pub))
+	if err != nil {
+		t.Fatalf("ParsePublicKey() error = %v", err)
+	}
+	if !got.Equal(pub) {
+		t.Error("ParsePublicKey() returned a different key")
+	}
+	if _, err := ParsePublicKey(base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+		t.Error("ParsePublicKey() with short key should fail")
+	}
+}
+
+// TestEmbeddedKeysParse verifies that the keys compiled into the binary are valid.
+func TestEmbeddedKeysParse(t *testing.T) {
+	if _, err := EmbeddedKeys(); err != nil {
+		t.Fatalf("EmbeddedKeys() error = %v", err)
+	}
+}
+
+// TestEmbeddedKeysIncludeReleaseKey verifies that the release key injected
+// at build time is trusted.
+func TestEmbeddedKeysIncludeReleaseKey(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(k string) { releaseKey = k }(releaseKey)
+	releaseKey = base64.StdEncoding.EncodeToString(pub)
+
+	keys, err := EmbeddedKeys()
+	if err != nil {
+		t.Fatalf("EmbeddedKeys() error = %v", err)
+	}
+	if !slices.ContainsFunc(keys, func(k ed25519.PublicKey) bool { return k.Equal(pub) }) {
+		t.Errorf("EmbeddedKeys() = %v, want the release key", keys)
+	}
+}
+
 // TestLoadRemoteFallsBackToLocalOverride verifies the full fallback chain:
 // remote fails → local override used.
 func TestLoadRemoteFallsBackToLocalOverride(t *testing.T) {
@@ -135,6 +315,32 @@ func TestLoadRemoteFallsBackToLocalOverride(t *testing.T) {
 	}
 }
 
+// testKey generates a throwaway ed25519 key pair.
+func testKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, priv
+}
+
+// signedServer serves data at any path and sig (base64) at *.sig.
+// A nil sig makes the signature endpoint return 404.
+func signedServer(data, sig []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, SignatureSuffix) {
+			if sig == nil {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString(sig)))
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+}
+
 // TestLoadInvalidJSON verifies that a corrupt override returns a parse error.
 func TestLoadInvalidJSON(t *testing.T) {
 	tmp := t.TempDir()
@@ -0,0 +1,212 @@
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Overlay is a partial manifest layered on top of the base one, typically to
+// add private components that never appear in the public manifest.
+//
+// Components whose ID already exists are merged field by field: only the
+// keys present in the overlay JSON replace the base values, so an overlay
+// can swap a package name without restating the rest. New IDs are appended.
+// Hide removes components (and their preset entries) from the result.
+type Overlay struct {
+	RegistryURL string            `json:"registryUrl,omitempty"`
+	Scopes      map[string]string `json:"scopes,omitempty"`
+	Core        []Package         `json:"core,omitempty"`
+	Services    []json.RawMessage `json:"services,omitempty"`
+	Plugins     []json.RawMessage `json:"plugins,omitempty"`
+	Presets     []Preset          `json:"presets,omitempty"`
+	Hide        []string          `json:"hide,omitempty"` // component IDs
+}
+
+// applyOverlays reads each location in opts.Overlays and merges it into m in
+// order. Every layer is validated against the merged result so a problem is
+// reported against the overlay that introduced it.
+func applyOverlays(m *Manifest, opts LoadOptions) (*Manifest, error) {
+	for _, loc := range opts.Overlays {
+		data, src, err := readOverlay(loc, opts)
+		if err != nil {
+			return nil, err
+		}
+		var o Overlay
+		if err := json.Unmarshal(data, &o); err != nil {
+			return nil, fmt.Errorf("parse overlay %s: %w", loc, err)
+		}
+		if err := m.apply(o); err != nil {
+			return nil, fmt.Errorf("overlay %s: %w", loc, err)
+		}
+		if problems := Validate(m); len(problems) > 0 {
+			return nil, fmt.Errorf("overlay %s: %w", loc, &ValidationError{Problems: problems})
+		}
+		m.Overlays = append(m.Overlays, src)
+	}
+	return m, nil
+}
+
+// readOverlay returns the overlay bytes from a file or, for URLs, a download
+// verified like a remote manifest. Overlays are explicit, so any failure is
+// fatal rather than silently installing without the private components.
+func readOverlay(loc string, opts LoadOptions) ([]byte, Source, error) {
+	if !IsRemote(loc) {
+		// #nosec G304 -- loc is an explicit CLI/env/config overlay path.
+		data, err := os.ReadFile(loc)
+		if err != nil {
+			return nil, Source{}, fmt.Errorf("read overlay %s: %w", loc, err)
+		}
+		// The location is recorded for update, which may run from anywhere.
+		abs, err := filepath.Abs(loc)
+		if err != nil {
+			return nil, Source{}, fmt.Errorf("overlay %s: %w", loc, err)
+		}
+		return data, Source{Kind: SourceOverride, Location: abs}, nil
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	res, err := fetch(ctx, loc, nil)
+	if err != nil {
+		return nil, Source{}, err
+	}
+	if res.status != http.StatusOK {
+		return nil, Source{}, fmt.Errorf("fetch %s: status %d", loc, res.status)
+	}
+	src := Source{Kind: SourceRemote, Location: loc}
+	if opts.Insecure {
+		opts.warn("signature verification disabled for %s", loc)
+		return res.body, src, nil
+	}
+	sigRes, err := fetch(ctx, loc+SignatureSuffix, nil)
+	if err != nil {
+		return nil, Source{}, err
+	}
+	if sigRes.status != http.StatusOK {
+		return nil, Source{}, fmt.Errorf("%w: overlay %s has no signature (status %d); pass --insecure-manifest to accept it", ErrSignature, loc, sigRes.status)
+	}
+	keys, err := EmbeddedKeys()
+	if err != nil {
+		return nil, Source{}, err
+	}
+	if err := Verify(res.body, sigRes.body, append(keys, opts.TrustedKeys...)); err != nil {
+		return nil, Source{}, fmt.Errorf("%s: %w", loc, err)
+	}
+	return res.body, src, nil
+}
+
+// apply merges o into m in place.
+func (m *Manifest) apply(o Overlay) error {
+	if o.RegistryURL != "" {
+		m.RegistryURL = o.RegistryURL
+	}
+	for scope, u := range o.Scopes {
+		if m.Scopes == nil {
+			m.Scopes = make(map[string]string, len(o.Scopes))
+		}
+		m.Scopes[scope] = u
+	}
+	for _, p := range o.Core {
+		if i := slices.IndexFunc(m.Core, func(c Package) bool { return c.Name == p.Name }); i >= 0 {
+			m.Core[i] = p
+		} else {
+			m.Core = append(m.Core, p)
+		}
+	}
+
+	var err error
+	if m.Services, err = mergeComponents(m.Services, o.Services); err != nil {
+		return fmt.Errorf("services: %w", err)
+	}
+	if m.Plugins, err = mergeComponents(m.Plugins, o.Plugins); err != nil {
+		return fmt.Errorf("plugins: %w", err)
+	}
+
+	for _, p := range o.Presets {
+		if i := slices.IndexFunc(m.Presets, func(q Preset) bool { return q.ID == p.ID }); i >= 0 {
+			m.Presets[i] = p
+		} else {
+			m.Presets = append(m.Presets, p)
+		}
+	}
+
+	if len(o.Hide) > 0 {
+		m.hide(o.Hide)
+	}
+	return nil
+}
+
+// mergeComponents merges raw overlay components into base by ID.
+func mergeComponents(base []Component, raw []json.RawMessage) ([]Component, error) {
+	for _, r := range raw {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(r, &fields); err != nil {
+			return nil, err
+		}
+		var id string
+		if err := json.Unmarshal(fields["id"], &id); err != nil || id == "" {
+			return nil, fmt.Errorf("component without id: %s", r)
+		}
+
+		i := slices.IndexFunc(base, func(c Component) bool { return c.ID == id })
+		if i < 0 {
+			var c Component
+			if err := json.Unmarshal(r, &c); err != nil {
+				return nil, fmt.Errorf("%s: %w", id, err)
+			}
+			base = append(base, c)
+			continue
+		}
+
+		// Round-trip the base through JSON so only the overlay's keys change.
+		merged, err := json.Marshal(base[i])
+		if err != nil {
+			return nil, err
+		}
+		var baseFields map[string]json.RawMessage
+		if err := json.Unmarshal(merged, &baseFields); err != nil {
+			return nil, err
+		}
+		for k, v := range fields {
+			baseFields[k] = v
+		}
+		if merged, err = json.Marshal(baseFields); err != nil {
+			return nil, err
+		}
+		var c Component
+		if err := json.Unmarshal(merged, &c); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		base[i] = c
+	}
+	return base, nil
+}
+
+// hide drops the given component IDs from m, from presets and from other
+// components' conflicts. Requirements are kept so that a visible component
+// depending on a hidden one fails validation instead of installing broken.
+func (m *Manifest) hide(ids []string) {
+	hidden := func(id string) bool { return slices.Contains(ids, id) }
+	m.Services = slices.DeleteFunc(m.Services, func(c Component) bool { return hidden(c.ID) })
+	m.Plugins = slices.DeleteFunc(m.Plugins, func(c Component) bool { return hidden(c.ID) })
+	for i := range m.Presets {
+		m.Presets[i].Services = slices.DeleteFunc(m.Presets[i].Services, hidden)
+		m.Presets[i].Plugins = slices.DeleteFunc(m.Presets[i].Plugins, hidden)
+	}
+	for _, cs := range [][]Component{m.Services, m.Plugins} {
+		for i := range cs {
+			cs[i].Conflicts = slices.DeleteFunc(cs[i].Conflicts, hidden)
+		}
+	}
+}
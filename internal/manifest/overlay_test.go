@@ -0,0 +1,124 @@
+package manifest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestLoadAppliesOverlays verifies that overlays add components, merge
+// fields into existing ones, hide components and replace the registry.
+func TestLoadAppliesOverlays(t *testing.T) {
+	dir := t.TempDir()
+	corp := writeManifest(t, dir, "corp.json", `{
+		"registryUrl": "https://npm.corp.example",
+		"scopes": { "@corp": "https://npm.corp.example/private" },
+		"plugins": [
+			{ "id": "corp-audit", "pkg": "@corp/kb-audit", "description": "Internal audit" },
+			{ "id": "mind", "pkg": "@corp/mind-fork" }
+		],
+		"hide": ["studio"]
+	}`)
+	extra := writeManifest(t, dir, "extra.json", `{ "presets": [{ "id": "corp", "description": "Corp", "services": [], "plugins": ["corp-audit"] }] }`)
+
+	m, err := Load(LoadOptions{Overlays: []string{corp, extra}})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if m.RegistryURL != "https://npm.corp.example" {
+		t.Errorf("RegistryURL = %q", m.RegistryURL)
+	}
+	if m.Scopes["@corp"] != "https://npm.corp.example/private" {
+		t.Errorf("Scopes = %v", m.Scopes)
+	}
+	if c, ok := m.Component("corp-audit"); !ok || c.Pkg != "@corp/kb-audit" {
+		t.Errorf("corp-audit = %+v, %v; want added", c, ok)
+	}
+	mind, _ := m.Component("mind")
+	if mind.Pkg != "@corp/mind-fork" || mind.Description == "" || !mind.Default {
+		t.Errorf("mind = %+v; want pkg replaced, other fields kept", mind)
+	}
+	if _, ok := m.Component("studio"); ok {
+		t.Error("studio still present after hide")
+	}
+	if _, ok := m.Preset("corp"); !ok {
+		t.Error("preset from second overlay missing")
+	}
+	if len(m.Overlays) != 2 || m.Overlays[0].Location != corp || m.Overlays[1].Location != extra {
+		t.Errorf("Overlays = %v, want both layers in order", m.Overlays)
+	}
+	if m.Source.Kind != SourceEmbedded {
+		t.Errorf("Source = %v, want embedded base", m.Source)
+	}
+}
+
+// TestOverlayRecordsAbsolutePath verifies that a relative overlay path is
+// recorded as an absolute one, so update finds it from anywhere.
+func TestOverlayRecordsAbsolutePath(t *testing.T) {
+	dir := t.TempDir()
+	path := writeManifest(t, dir, "corp.json", `{ "hide": ["studio"] }`)
+	t.Chdir(dir)
+
+	m, err := Load(LoadOptions{Overlays: []string{"corp.json"}})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(m.Overlays) != 1 || m.Overlays[0].Location != path {
+		t.Errorf("Overlays = %v, want %s", m.Overlays, path)
+	}
+}
+
+// TestOverlayHideDropsPresetEntries verifies that hidden components are
+// removed from presets too.
+func TestOverlayHideDropsPresetEntries(t *testing.T) {
+	path := writeManifest(t, t.TempDir(), "hide.json", `{ "hide": ["commit"] }`)
+	m, err := Load(LoadOptions{Overlays: []string{path}})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	p, _ := m.Preset("ci-runner")
+	for _, id := range p.Plugins {
+		if id == "commit" {
+			t.Errorf("ci-runner plugins = %v, still lists hidden commit", p.Plugins)
+		}
+	}
+}
+
+// TestOverlayValidationNamesLayer verifies that a merge producing an invalid
+// manifest fails with the overlay's location in the error.
+func TestOverlayValidationNamesLayer(t *testing.T) {
+	path := writeManifest(t, t.TempDir(), "broken.json", `{ "hide": ["workflow"] }`) // agents requires workflow
+	_, err := Load(LoadOptions{Overlays: []string{path}})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || !strings.Contains(err.Error(), path) {
+		t.Fatalf("Load() error = %v, want validation error naming %s", err, path)
+	}
+}
+
+// TestOverlayMissingFileIsFatal verifies that a missing overlay is an error,
+// not a silent install without the private components.
+func TestOverlayMissingFileIsFatal(t *testing.T) {
+	if _, err := Load(LoadOptions{Overlays: []string{"/nonexistent/corp.json"}}); err == nil {
+		t.Fatal("Load() with missing overlay should fail")
+	}
+}
+
+// TestOverlayRemoteRequiresSignature verifies that remote overlays are
+// verified like remote manifests.
+func TestOverlayRemoteRequiresSignature(t *testing.T) {
+	srv := signedServer([]byte(`{ "hide": ["studio"] }`), nil)
+	defer srv.Close()
+
+	_, err := Load(LoadOptions{Overlays: []string{srv.URL + "/corp.json"}})
+	if !errors.Is(err, ErrSignature) {
+		t.Fatalf("Load() error = %v, want ErrSignature", err)
+	}
+	m, err := Load(LoadOptions{Overlays: []string{srv.URL + "/corp.json"}, Insecure: true})
+	if err != nil {
+		t.Fatalf("Load(insecure) error = %v", err)
+	}
+	if len(m.Overlays) != 1 || m.Overlays[0].Kind != SourceRemote {
+		t.Errorf("Overlays = %v, want one remote layer", m.Overlays)
+	}
+}
@@ -0,0 +1,96 @@
+package manifest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Port is a localhost TCP port a service listens on.
+type Port struct {
+	Name string `json:"name,omitempty"` // empty for the service's main port
+	Port int    `json:"port"`
+}
+
+// ServicePort is a service's port with any user override applied.
+type ServicePort struct {
+	Key     string // override key, see PortKey
+	Service string // service ID
+	Port    int
+}
+
+// PortKey returns the key that overrides port p of service id:
+// "rest" for the main port, "rest.admin" for a named one.
+func PortKey(id string, p Port) string {
+	if p.Name == "" {
+		return id
+	}
+	return id + "." + p.Name
+}
+
+// ServicePorts returns the ports of the given services in manifest order,
+// with overrides (keyed by PortKey) applied. A nil ids means all services
+// except deprecated ones.
+func (m *Manifest) ServicePorts(ids []string, overrides map[string]int) []ServicePort {
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	var out []ServicePort
+	for _, c := range m.Services {
+		if ids != nil && !want[c.ID] || ids == nil && c.IsDeprecated() {
+			continue
+		}
+		for _, p := range c.Ports {
+			key := PortKey(c.ID, p)
+			port := p.Port
+			if o, ok := overrides[key]; ok {
+				port = o
+			}
+			out = append(out, ServicePort{Key: key, Service: c.ID, Port: port})
+		}
+	}
+	return out
+}
+
+// CheckPortOverrides returns an error if an override names a port the
+// manifest doesn't declare, is out of range, or takes a port another service
+// port ends up on once all overrides are applied.
+func (m *Manifest) CheckPortOverrides(overrides map[string]int) error {
+	known := make(map[string]bool)
+	for _, sp := range m.ServicePorts(nil, nil) {
+		known[sp.Key] = true
+	}
+	keys := make([]string, 0, len(overrides))
+	for k := range overrides {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !known[k] {
+			names := make([]string, 0, len(known))
+			for n := range known {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("unknown service port %q (available: %s)", k, strings.Join(names, ", "))
+		}
+		if p := overrides[k]; p < 1 || p > 65535 {
+			return fmt.Errorf("port %s=%d: must be between 1 and 65535", k, p)
+		}
+	}
+	owner := make(map[int]string)
+	for _, sp := range m.ServicePorts(nil, overrides) {
+		other, taken := owner[sp.Port]
+		if !taken {
+			owner[sp.Port] = sp.Key
+			continue
+		}
+		key := sp.Key
+		if _, ok := overrides[key]; !ok {
+			key, other = other, key
+		}
+		return fmt.Errorf("port %s=%d: already used by %s", key, sp.Port, other)
+	}
+	return nil
+}
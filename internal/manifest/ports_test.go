@@ -0,0 +1,84 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+)
+
+// portsManifest returns a manifest with a single-port and a two-port service.
+func portsManifest() *Manifest {
+	return &Manifest{
+		Version: "1.0.0",
+		Core:    []Package{{Name: "@kb-labs/cli-bin"}},
+		Services: []Component{
+			{ID: "rest", Pkg: "@kb-labs/rest-api", Ports: []Port{{Port: 5050}}},
+			{ID: "studio", Pkg: "@kb-labs/studio", Ports: []Port{{Port: 3000}, {Name: "hmr", Port: 3001}}},
+		},
+	}
+}
+
+// TestServicePortsAppliesOverrides verifies keys, filtering and overrides.
+func TestServicePortsAppliesOverrides(t *testing.T) {
+	m := portsManifest()
+
+	got := m.ServicePorts([]string{"studio"}, map[string]int{"studio.hmr": 4001})
+	want := []ServicePort{{Key: "studio", Service: "studio", Port: 3000}, {Key: "studio.hmr", Service: "studio", Port: 4001}}
+	if len(got) != len(want) {
+		t.Fatalf("ServicePorts() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ServicePorts()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if all := m.ServicePorts(nil, nil); len(all) != 3 {
+		t.Errorf("ServicePorts(nil) = %v, want all 3 ports", all)
+	}
+}
+
+// TestCheckPortOverrides verifies that unknown keys, out-of-range ports and
+// ports that collide with another service port are rejected.
+func TestCheckPortOverrides(t *testing.T) {
+	m := portsManifest()
+	if err := m.CheckPortOverrides(map[string]int{"rest": 5051, "studio.hmr": 3002}); err != nil {
+		t.Errorf("CheckPortOverrides(valid) error = %v", err)
+	}
+	if err := m.CheckPortOverrides(map[string]int{"mind": 1}); err == nil || !strings.Contains(err.Error(), "unknown service port") {
+		t.Errorf("CheckPortOverrides(unknown) error = %v", err)
+	}
+	if err := m.CheckPortOverrides(map[string]int{"rest": 70000}); err == nil {
+		t.Error("CheckPortOverrides(70000) should fail")
+	}
+	if err := m.CheckPortOverrides(map[string]int{"rest": 3001}); err == nil || !strings.Contains(err.Error(), "rest=3001: already used by studio.hmr") {
+		t.Errorf("CheckPortOverrides(default port of another service) error = %v", err)
+	}
+	if err := m.CheckPortOverrides(map[string]int{"rest": 6000, "studio": 6000}); err == nil || !strings.Contains(err.Error(), "already used by") {
+		t.Errorf("CheckPortOverrides(same port twice) error = %v", err)
+	}
+	// Swapping two ports is fine: only the result has to be unique.
+	if err := m.CheckPortOverrides(map[string]int{"studio": 3001, "studio.hmr": 3000}); err != nil {
+		t.Errorf("CheckPortOverrides(swap) error = %v", err)
+	}
+}
+
+// TestValidatePorts verifies range, duplicate and placement checks.
+func TestValidatePorts(t *testing.T) {
+	m := portsManifest()
+	m.Services[1].Ports = append(m.Services[1].Ports, Port{Port: 5050}, Port{Name: "x", Port: 0})
+	m.Plugins = []Component{{ID: "mind", Pkg: "@kb-labs/mind", Ports: []Port{{Port: 9000}}}}
+
+	got := make(map[string]bool)
+	for _, p := range Validate(m) {
+		got[p.Path] = true
+	}
+	for _, path := range []string{
+		"$.services[1].ports[2].name", // second unnamed port
+		"$.services[1].ports[2].port", // 5050 already used by rest
+		"$.services[1].ports[3].port", // out of range
+		"$.plugins[0].ports",
+	} {
+		if !got[path] {
+			t.Errorf("missing problem at %s; got %v", path, got)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// trustedKeys lists the base64-encoded ed25519 public keys baked into the
+// binary. Remote manifests signed by any of them are accepted.
+//
+//go:embed trusted_keys.txt
+var trustedKeys []byte
+
+// releaseKey is the base64-encoded public key of the release manifest
+// signing key. Release builds set it with
+// -ldflags "-X github.com/kb-labs/create/internal/manifest.releaseKey=<key>"
+// so that the published manifest verifies without extra flags.
+var releaseKey string
+
+// SignatureSuffix is appended to a manifest URL to locate its detached signature.
+const SignatureSuffix = ".sig"
+
+// ErrSignature is returned (wrapped) when a remote manifest is unsigned or its
+// signature does not match any trusted key. Unlike network errors it is never
+// recovered by falling back to another source.
+var ErrSignature = errors.New("manifest signature verification failed")
+
+// ParsePublicKey decodes a base64-encoded ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("decode public key: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key: got %d bytes, want %d", len(raw), ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// EmbeddedKeys returns the public keys compiled into the binary: the
+// release key, if set, and those in trusted_keys.txt.
+func EmbeddedKeys() ([]ed25519.PublicKey, error) {
+	var keys []ed25519.PublicKey
+	if releaseKey != "" {
+		k, err := ParsePublicKey(releaseKey)
+		if err != nil {
+			return nil, fmt.Errorf("release key: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(trustedKeys))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, err := ParsePublicKey(line)
+		if err != nil {
+			return nil, fmt.Errorf("embedded key: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, scanner.Err()
+}
+
+// Verify checks a detached base64-encoded ed25519 signature of data against
+// keys. It succeeds if any key matches.
+func Verify(data, sig []byte, keys []ed25519.PublicKey) error {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return fmt.Errorf("%w: decode signature: %v", ErrSignature, err)
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("%w: no trusted public keys configured", ErrSignature)
+	}
+	for _, k := range keys {
+		if ed25519.Verify(k, data, raw) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: signature does not match any trusted key", ErrSignature)
+}
@@ -1,25 +1,178 @@
 package manifest
 
+import "crypto/ed25519"
+
 // Package is a core npm package required by the platform.
 type Package struct {
 	Name string `json:"name"`
+	// Version is an optional semver range (e.g. "^1.2.0"). Empty means latest.
+	Version string `json:"version,omitempty"`
 }
 
+// Spec returns the npm install spec for the package.
+func (p Package) Spec() string { return spec(p.Name, p.Version) }
+
 // Component is an optional service or plugin.
 type Component struct {
 	ID          string `json:"id"`
 	Pkg         string `json:"pkg"`
+	Version     string `json:"version,omitempty"` // semver range, empty = latest
 	Description string `json:"description"`
 	Default     bool   `json:"default"`
+	// Requires lists component IDs that must be installed alongside this one.
+	Requires []string `json:"requires,omitempty"`
+	// Conflicts lists component IDs that cannot be installed together with this one.
+	Conflicts []string `json:"conflicts,omitempty"`
+	// Constraints limits the node version, OS and arch this component supports.
+	Constraints Constraints `json:"constraints,omitzero"`
+	// PostInstall lists commands run after the package is installed or updated.
+	PostInstall []Step `json:"postInstall,omitempty"`
+	// Ports lists the localhost ports a service listens on. Services only.
+	Ports []Port `json:"ports,omitempty"`
+	// Config describes the component's entry in the project's kb.config.jsonc.
+	Config ConfigSection `json:"config,omitzero"`
+	// Deprecated, if set, says why the component is no longer offered. It is
+	// hidden from new installs but kept so existing installs can migrate.
+	Deprecated string `json:"deprecated,omitempty"`
+	// ReplacedBy is the ID of the component that update installs in place of
+	// this deprecated one.
+	ReplacedBy string `json:"replacedBy,omitempty"`
+}
+
+// ConfigSection is what the scaffold writes for a component in the project
+// config. Services are written as on/off toggles; plugins get an object with
+// "enabled" followed by the Snippet lines.
+type ConfigSection struct {
+	// Description is the comment above the entry. Defaults to the
+	// component's Description.
+	Description string `json:"description,omitempty"`
+	// Snippet holds JSONC members (comments allowed), one line per element,
+	// e.g. ["// Max steps per agent run.", "\"maxSteps\": 25"]. Plugins only.
+	Snippet []string `json:"snippet,omitempty"`
+}
+
+// ConfigDescription returns the comment the scaffold writes above the
+// component's config entry.
+func (c Component) ConfigDescription() string {
+	if c.Config.Description != "" {
+		return c.Config.Description
+	}
+	return c.Description
+}
+
+// IsDeprecated reports whether the component is deprecated.
+func (c Component) IsDeprecated() bool { return c.Deprecated != "" }
+
+// Spec returns the npm install spec for the component's package.
+func (c Component) Spec() string { return spec(c.Pkg, c.Version) }
+
+// Step is a one-off setup command run in the platform directory, e.g.
+// building a native index or running migrations. Run is an argv list and is
+// not passed through a shell; binaries in node_modules/.bin are found first.
+type Step struct {
+	Name string   `json:"name"` // shown as the progress label
+	Run  []string `json:"run"`
+}
+
+// Preset is a named set of services and plugins (e.g. "minimal", "ai-full")
+// offered as a starting point instead of ticking checkboxes one by one.
+type Preset struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description"`
+	Services    []string `json:"services"` // service IDs
+	Plugins     []string `json:"plugins"`  // plugin IDs
 }
 
+// DefaultChannel is the release channel of manifests that don't declare one.
+const DefaultChannel = "stable"
+
 // Manifest describes all installable parts of the KB Labs platform.
 type Manifest struct {
-	Version     string      `json:"version"`
-	RegistryURL string      `json:"registryUrl"`
-	Core        []Package   `json:"core"`
-	Services    []Component `json:"services"`
-	Plugins     []Component `json:"plugins"`
+	Version     string `json:"version"`
+	RegistryURL string `json:"registryUrl"`
+	// Scopes maps npm scopes ("@corp") to the registry their packages are
+	// installed from, overriding RegistryURL.
+	Scopes   map[string]string `json:"scopes,omitempty"`
+	Core     []Package         `json:"core"`
+	Services []Component       `json:"services"`
+	Plugins  []Component       `json:"plugins"`
+	Presets  []Preset          `json:"presets,omitempty"`
+	// Constraints applies to the whole platform (e.g. a minimum node version).
+	Constraints Constraints `json:"constraints,omitzero"`
+
+	// MinInstallerVersion is the oldest kb-create release that understands
+	// this manifest's schema. Older binaries refuse to load it.
+	MinInstallerVersion string `json:"minInstallerVersion,omitempty"`
+
+	// Channel is the release channel this manifest belongs to. Empty = stable.
+	Channel string `json:"channel,omitempty"`
+	// Channels maps release channel names (e.g. "beta", "nightly") to the
+	// URL or file path of that channel's manifest.
+	Channels map[string]string `json:"channels,omitempty"`
+
+	// Source is set by Load and is not part of the manifest JSON.
+	Source Source `json:"-"`
+	// BaseSource is where the manifest whose Channels map led Load to this
+	// one came from. It is zero unless Load switched channels.
+	BaseSource Source `json:"-"`
+	// Overlays records, in order, where each overlay merged by Load came from.
+	Overlays []Source `json:"-"`
+	// TrustedKeys records the extra keys Load was given, so later loads of
+	// the same source can be verified the same way.
+	TrustedKeys []ed25519.PublicKey `json:"-"`
+}
+
+// Source kinds reported by Load.
+const (
+	SourceRemote   = "remote"
+	SourceCache    = "cache" // last good copy of a remote manifest, used offline
+	SourceOverride = "override"
+	SourceEmbedded = "embedded"
+	SourceBundle   = "bundle" // snapshot inside an offline install bundle
+)
+
+// Source describes where a manifest was actually loaded from.
+type Source struct {
+	Kind     string `json:"kind"`               // SourceRemote, SourceCache, SourceOverride, SourceEmbedded or SourceBundle
+	Location string `json:"location,omitempty"` // URL or file path; empty for embedded
+}
+
+// String returns a human-readable form, e.g. "remote (https://…)".
+func (s Source) String() string {
+	if s.Kind == "" {
+		return "unknown"
+	}
+	if s.Location == "" {
+		return s.Kind
+	}
+	return s.Kind + " (" + s.Location + ")"
+}
+
+// Preset returns the preset with the given ID.
+func (m *Manifest) Preset(id string) (Preset, bool) {
+	for _, p := range m.Presets {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// PresetIDs returns the IDs of all presets in manifest order.
+func (m *Manifest) PresetIDs() []string {
+	ids := make([]string, len(m.Presets))
+	for i, p := range m.Presets {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+// ChannelName returns the manifest's release channel, defaulting to stable.
+func (m *Manifest) ChannelName() string {
+	if m.Channel == "" {
+		return DefaultChannel
+	}
+	return m.Channel
 }
 
 // CorePackageNames returns plain package name strings from Core.
@@ -30,3 +183,20 @@ func (m *Manifest) CorePackageNames() []string {
 	}
 	return names
 }
+
+// CorePackageSpecs returns install specs ("name" or "name@range") from Core.
+func (m *Manifest) CorePackageSpecs() []string {
+	specs := make([]string, len(m.Core))
+	for i, p := range m.Core {
+		specs[i] = p.Spec()
+	}
+	return specs
+}
+
+// spec joins a package name and an optional version range as "name@range".
+func spec(name, version string) string {
+	if version == "" {
+		return name
+	}
+	return name + "@" + version
+}
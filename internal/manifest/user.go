@@ -0,0 +1,43 @@
+package manifest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UserPluginDescription is the description given to plugins added by the
+// user rather than listed in the manifest.
+const UserPluginDescription = "Third-party plugin (user-added)"
+
+// ParseSpec splits an npm install spec into package name and version range:
+// "@acme/foo@^1.2" → ("@acme/foo", "^1.2"), "foo" → ("foo", "").
+func ParseSpec(spec string) (name, version string) {
+	spec = strings.TrimSpace(spec)
+	// Skip a leading scope "@" when looking for the version separator.
+	if i := strings.LastIndex(spec, "@"); i > 0 {
+		return spec[:i], spec[i+1:]
+	}
+	return spec, ""
+}
+
+// UserPlugin returns the component for a third-party plugin given as an npm
+// spec. Its ID is the package name, so it never collides with manifest IDs;
+// packages the manifest already lists must be selected by their manifest ID,
+// and core packages are always installed.
+func (m *Manifest) UserPlugin(spec string) (Component, error) {
+	name, version := ParseSpec(spec)
+	if len(name) > maxNpmNameLen || !npmNameRe.MatchString(name) {
+		return Component{}, fmt.Errorf("%q is not a valid npm package name", name)
+	}
+	for _, p := range m.Core {
+		if p.Name == name {
+			return Component{}, fmt.Errorf("%s is a core package and is always installed", name)
+		}
+	}
+	for _, c := range append(append([]Component{}, m.Services...), m.Plugins...) {
+		if c.Pkg == name || c.ID == name {
+			return Component{}, fmt.Errorf("%s is already in the manifest as %q; select it by ID instead", name, c.ID)
+		}
+	}
+	return Component{ID: name, Pkg: name, Version: version, Description: UserPluginDescription}, nil
+}
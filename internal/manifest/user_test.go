@@ -0,0 +1,49 @@
+package manifest
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseSpec verifies that scoped names keep their leading "@".
+func TestParseSpec(t *testing.T) {
+	tests := []struct{ spec, name, version string }{
+		{"foo", "foo", ""},
+		{"foo@^1.2", "foo", "^1.2"},
+		{"@acme/kb-plugin-foo", "@acme/kb-plugin-foo", ""},
+		{"@acme/kb-plugin-foo@next", "@acme/kb-plugin-foo", "next"},
+	}
+	for _, tt := range tests {
+		name, version := ParseSpec(tt.spec)
+		if name != tt.name || version != tt.version {
+			t.Errorf("ParseSpec(%q) = (%q, %q), want (%q, %q)", tt.spec, name, version, tt.name, tt.version)
+		}
+	}
+}
+
+// TestUserPlugin verifies the component built for a third-party spec and
+// that invalid names and manifest packages are rejected.
+func TestUserPlugin(t *testing.T) {
+	m := &Manifest{
+		Core:    []Package{{Name: "@kb-labs/cli-bin"}},
+		Plugins: []Component{{ID: "mind", Pkg: "@kb-labs/mind"}},
+	}
+
+	c, err := m.UserPlugin("@acme/kb-plugin-foo@^1.0.0")
+	if err != nil {
+		t.Fatalf("UserPlugin() error = %v", err)
+	}
+	if c.ID != "@acme/kb-plugin-foo" || c.Pkg != c.ID || c.Version != "^1.0.0" || c.Description != UserPluginDescription {
+		t.Errorf("UserPlugin() = %+v", c)
+	}
+
+	if _, err := m.UserPlugin("Not A Package"); err == nil {
+		t.Error("UserPlugin(invalid name) should fail")
+	}
+	if _, err := m.UserPlugin("@kb-labs/mind@2"); err == nil || !strings.Contains(err.Error(), `as "mind"`) {
+		t.Errorf("UserPlugin(manifest package) error = %v", err)
+	}
+	if _, err := m.UserPlugin("@kb-labs/cli-bin"); err == nil || !strings.Contains(err.Error(), "core package") {
+		t.Errorf("UserPlugin(core package) error = %v", err)
+	}
+}
@@ -0,0 +1,271 @@
+package manifest
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kb-labs/create/internal/semver"
+)
+
+// npmNameRe matches valid npm package names, optionally scoped
+// (same rules as validate-npm-package-name for new packages).
+var npmNameRe = regexp.MustCompile(`^(@[a-z0-9-~][a-z0-9-._~]*/)?[a-z0-9-~][a-z0-9-._~]*$`)
+
+// maxNpmNameLen is the npm registry limit on package name length.
+const maxNpmNameLen = 214
+
+// Problem is a single validation finding located by its JSON path.
+type Problem struct {
+	Path    string // e.g. "$.plugins[2].pkg"
+	Message string
+}
+
+func (p Problem) String() string { return p.Path + ": " + p.Message }
+
+// ValidationError reports every problem found in a manifest.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = p.String()
+	}
+	return fmt.Sprintf("invalid manifest (%d problems):\n  %s", len(e.Problems), strings.Join(lines, "\n  "))
+}
+
+// Validate checks m for structural problems and returns all of them.
+// It returns nil when the manifest is valid.
+func Validate(m *Manifest) []Problem {
+	v := &validator{ids: make(map[string]string)}
+
+	if strings.TrimSpace(m.Version) == "" {
+		v.add("$.version", "is required")
+	}
+	if m.MinInstallerVersion != "" {
+		if _, err := semver.Parse(m.MinInstallerVersion); err != nil {
+			v.add("$.minInstallerVersion", err.Error())
+		}
+	}
+	if m.RegistryURL != "" {
+		v.registryURL("$.registryUrl", m.RegistryURL)
+	}
+	scopes := make([]string, 0, len(m.Scopes))
+	for scope := range m.Scopes {
+		scopes = append(scopes, scope)
+	}
+	sort.Strings(scopes)
+	for _, scope := range scopes {
+		path := "$.scopes." + scope
+		if len(scope) < 2 || scope[0] != '@' || strings.ContainsAny(scope[1:], "@/ ") {
+			v.add(path, fmt.Sprintf("%q is not an npm scope like \"@corp\"", scope))
+		}
+		v.registryURL(path, m.Scopes[scope])
+	}
+	channels := make([]string, 0, len(m.Channels))
+	for name := range m.Channels {
+		channels = append(channels, name)
+	}
+	sort.Strings(channels)
+	for _, name := range channels {
+		if strings.TrimSpace(m.Channels[name]) == "" {
+			v.add("$.channels."+name, "location is required")
+		}
+	}
+	v.constraints("$.constraints", m.Constraints)
+	for i, p := range m.Core {
+		v.pkgName(fmt.Sprintf("$.core[%d].name", i), p.Name)
+	}
+	v.components("services", m.Services)
+	v.components("plugins", m.Plugins)
+	v.ports(m)
+	v.references("services", m.Services)
+	v.references("plugins", m.Plugins)
+	v.deprecations("services", m.Services)
+	v.deprecations("plugins", m.Plugins)
+	v.presets(m)
+
+	return v.problems
+}
+
+type validator struct {
+	ids      map[string]string // component ID → JSON path of first occurrence
+	problems []Problem
+}
+
+func (v *validator) add(path, msg string) {
+	v.problems = append(v.problems, Problem{Path: path, Message: msg})
+}
+
+// registryURL checks that raw is an absolute http(s) URL.
+func (v *validator) registryURL(path, raw string) {
+	if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.add(path, fmt.Sprintf("%q is not an http(s) URL", raw))
+	}
+}
+
+// components validates a services/plugins list. IDs must be unique across
+// both lists since the scaffold and selection address components by ID alone.
+func (v *validator) components(section string, cs []Component) {
+	for i, c := range cs {
+		base := fmt.Sprintf("$.%s[%d]", section, i)
+		switch {
+		case strings.TrimSpace(c.ID) == "":
+			v.add(base+".id", "is required")
+		case v.ids[c.ID] != "":
+			v.add(base+".id", fmt.Sprintf("duplicate id %q (first defined at %s)", c.ID, v.ids[c.ID]))
+		default:
+			v.ids[c.ID] = base + ".id"
+		}
+		v.pkgName(base+".pkg", c.Pkg)
+		v.constraints(base+".constraints", c.Constraints)
+		if section == "services" && len(c.Config.Snippet) > 0 {
+			v.add(base+".config.snippet", "is only supported for plugins; services are on/off toggles")
+		}
+		for j, st := range c.PostInstall {
+			path := fmt.Sprintf("%s.postInstall[%d]", base, j)
+			if strings.TrimSpace(st.Name) == "" {
+				v.add(path+".name", "is required")
+			}
+			if len(st.Run) == 0 || strings.TrimSpace(st.Run[0]) == "" {
+				v.add(path+".run", "must name a command")
+			}
+		}
+	}
+}
+
+// ports checks that only services declare ports, that each is in range and
+// that no two services claim the same port or override key. A deprecated
+// service may share its port with its replacement.
+func (v *validator) ports(m *Manifest) {
+	byPort := make(map[int]string)
+	for i, c := range m.Plugins {
+		if len(c.Ports) > 0 {
+			v.add(fmt.Sprintf("$.plugins[%d].ports", i), "is only supported for services")
+		}
+	}
+	for i, c := range m.Services {
+		keys := make(map[string]bool, len(c.Ports))
+		for j, p := range c.Ports {
+			path := fmt.Sprintf("$.services[%d].ports[%d]", i, j)
+			key := PortKey(c.ID, p)
+			if keys[key] {
+				v.add(path+".name", fmt.Sprintf("duplicate port name %q", p.Name))
+			}
+			keys[key] = true
+			switch {
+			case p.Port < 1 || p.Port > 65535:
+				v.add(path+".port", fmt.Sprintf("%d is not between 1 and 65535", p.Port))
+			case c.IsDeprecated():
+			case byPort[p.Port] != "":
+				v.add(path+".port", fmt.Sprintf("%d is already used by %s", p.Port, byPort[p.Port]))
+			default:
+				byPort[p.Port] = key
+			}
+		}
+	}
+}
+
+func (v *validator) constraints(path string, c Constraints) {
+	if c.Node == "" {
+		return
+	}
+	if _, err := semver.Satisfies("0.0.0", c.Node); err != nil {
+		v.add(path+".node", err.Error())
+	}
+}
+
+// references checks that requires/conflicts point at known components.
+// It runs after components() so every ID is already registered.
+func (v *validator) references(section string, cs []Component) {
+	for i, c := range cs {
+		refs := []struct {
+			field string
+			ids   []string
+		}{{"requires", c.Requires}, {"conflicts", c.Conflicts}}
+		for _, r := range refs {
+			for j, id := range r.ids {
+				path := fmt.Sprintf("$.%s[%d].%s[%d]", section, i, r.field, j)
+				switch {
+				case id == c.ID:
+					v.add(path, "component cannot reference itself")
+				case v.ids[id] == "":
+					v.add(path, fmt.Sprintf("unknown component %q", id))
+				}
+			}
+		}
+	}
+}
+
+// deprecations checks that deprecated components aren't installed by default
+// and are replaced by a current component from the same section, so update
+// never migrates a plugin into a service or through a chain of renames.
+func (v *validator) deprecations(section string, cs []Component) {
+	for i, c := range cs {
+		base := fmt.Sprintf("$.%s[%d]", section, i)
+		if c.IsDeprecated() && c.Default {
+			v.add(base+".default", "a deprecated component cannot be installed by default")
+		}
+		if c.ReplacedBy == "" {
+			continue
+		}
+		path := base + ".replacedBy"
+		j := slices.IndexFunc(cs, func(r Component) bool { return r.ID == c.ReplacedBy })
+		switch {
+		case !c.IsDeprecated():
+			v.add(path, "requires deprecated to be set")
+		case c.ReplacedBy == c.ID:
+			v.add(path, "component cannot replace itself")
+		case j < 0:
+			v.add(path, fmt.Sprintf("unknown %s %q", strings.TrimSuffix(section, "s"), c.ReplacedBy))
+		case cs[j].IsDeprecated():
+			v.add(path, fmt.Sprintf("%q is deprecated too; point at its replacement", c.ReplacedBy))
+		}
+	}
+}
+
+// presets checks that preset IDs are unique and that every referenced ID
+// exists in the matching section.
+func (v *validator) presets(m *Manifest) {
+	seen := make(map[string]bool, len(m.Presets))
+	for i, p := range m.Presets {
+		base := fmt.Sprintf("$.presets[%d]", i)
+		switch {
+		case strings.TrimSpace(p.ID) == "":
+			v.add(base+".id", "is required")
+		case seen[p.ID]:
+			v.add(base+".id", fmt.Sprintf("duplicate preset id %q", p.ID))
+		}
+		seen[p.ID] = true
+		for j, id := range p.Services {
+			if !m.IsService(id) {
+				v.add(fmt.Sprintf("%s.services[%d]", base, j), fmt.Sprintf("unknown service %q", id))
+			} else if c, _ := m.Component(id); c.IsDeprecated() {
+				v.add(fmt.Sprintf("%s.services[%d]", base, j), fmt.Sprintf("service %q is deprecated", id))
+			}
+		}
+		for j, id := range p.Plugins {
+			if c, ok := m.Component(id); !ok || m.IsService(id) {
+				v.add(fmt.Sprintf("%s.plugins[%d]", base, j), fmt.Sprintf("unknown plugin %q", id))
+			} else if c.IsDeprecated() {
+				v.add(fmt.Sprintf("%s.plugins[%d]", base, j), fmt.Sprintf("plugin %q is deprecated", id))
+			}
+		}
+	}
+}
+
+func (v *validator) pkgName(path, name string) {
+	switch {
+	case strings.TrimSpace(name) == "":
+		v.add(path, "is required")
+	case len(name) > maxNpmNameLen:
+		v.add(path, fmt.Sprintf("%q exceeds %d characters", name, maxNpmNameLen))
+	case !npmNameRe.MatchString(name):
+		v.add(path, fmt.Sprintf("%q is not a valid npm package name", name))
+	}
+}
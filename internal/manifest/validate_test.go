@@ -0,0 +1,216 @@
+package manifest
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestValidateEmbedded verifies that the shipped manifest has no problems.
+func TestValidateEmbedded(t *testing.T) {
+	m, err := LoadDefault()
+	if err != nil {
+		t.Fatalf("LoadDefault() error = %v", err)
+	}
+	if problems := Validate(m); len(problems) != 0 {
+		t.Errorf("Validate(embedded) = %v, want none", problems)
+	}
+}
+
+// TestValidateReportsEveryProblem verifies that all problems are returned
+// at once, each located by its JSON path.
+func TestValidateReportsEveryProblem(t *testing.T) {
+	m := &Manifest{
+		RegistryURL: "ftp://registry",
+		Scopes:      map[string]string{"@corp": "https://npm.corp.example", "corp": "npm.corp.example"},
+		Core:        []Package{{Name: "@kb-labs/sdk"}, {Name: ""}},
+		Services: []Component{
+			{ID: "rest", Pkg: "@kb-labs/rest-api"},
+			{ID: "", Pkg: "@kb-labs/studio"},
+		},
+		Plugins: []Component{
+			{ID: "rest", Pkg: "@kb-labs/mind"},
+			{ID: "agents", Pkg: "Not A Valid/name"},
+			{ID: "commit", Pkg: ""},
+		},
+	}
+
+	got := make(map[string]bool)
+	for _, p := range Validate(m) {
+		got[p.Path] = true
+	}
+
+	want := []string{
+		"$.version",
+		"$.registryUrl",
+		"$.scopes.corp",
+		"$.core[1].name",
+		"$.services[1].id",
+		"$.plugins[0].id",
+		"$.plugins[1].pkg",
+		"$.plugins[2].pkg",
+	}
+	for _, path := range want {
+		if !got[path] {
+			t.Errorf("missing problem at %s; got %v", path, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d problems, want %d: %v", len(got), len(want), got)
+	}
+}
+
+// TestValidateNpmNames verifies npm package name rules.
+func TestValidateNpmNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"@kb-labs/mind", true},
+		{"lodash", true},
+		{"kb.plugin_x~1", true},
+		{"@Scope/pkg", false},
+		{"UpperCase", false},
+		{".hidden", false},
+		{"_private", false},
+		{"has space", false},
+		{"@scope/", false},
+	}
+	for _, tt := range tests {
+		m := &Manifest{Version: "1.0.0", Core: []Package{{Name: tt.name}}}
+		if gotValid := len(Validate(m)) == 0; gotValid != tt.valid {
+			t.Errorf("name %q: valid = %v, want %v", tt.name, gotValid, tt.valid)
+		}
+	}
+}
+
+// TestParseReturnsValidationError verifies that Parse (and therefore Load)
+// rejects structurally invalid manifests with a *ValidationError.
+func TestParseReturnsValidationError(t *testing.T) {
+	_, err := Parse([]byte(`{"services":[{"id":"rest","pkg":""}]}`))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Parse() error = %v, want *ValidationError", err)
+	}
+	if len(verr.Problems) != 2 {
+		t.Errorf("Problems = %v, want 2 (version + pkg)", verr.Problems)
+	}
+}
+
+// TestValidatePresets verifies that presets must reference components from
+// the matching section and have unique IDs.
+func TestValidatePresets(t *testing.T) {
+	m := &Manifest{
+		Version:  "1.0.0",
+		Services: []Component{{ID: "rest", Pkg: "@kb-labs/rest-api"}},
+		Plugins:  []Component{{ID: "mind", Pkg: "@kb-labs/mind"}},
+		Presets: []Preset{
+			{ID: "ok", Services: []string{"rest"}, Plugins: []string{"mind"}},
+			{ID: "ok", Services: []string{"mind"}, Plugins: []string{"rest"}},
+		},
+	}
+
+	got := make(map[string]bool)
+	for _, p := range Validate(m) {
+		got[p.Path] = true
+	}
+	for _, path := range []string{"$.presets[1].id", "$.presets[1].services[0]", "$.presets[1].plugins[0]"} {
+		if !got[path] {
+			t.Errorf("missing problem at %s; got %v", path, got)
+		}
+	}
+	if len(got) != 3 {
+		t.Errorf("got %d problems, want 3: %v", len(got), got)
+	}
+}
+
+// TestValidatePostInstall verifies that post-install steps need a name and
+// a command.
+func TestValidatePostInstall(t *testing.T) {
+	m := &Manifest{
+		Version: "1.0.0",
+		Core:    []Package{{Name: "@kb-labs/cli-bin"}},
+		Plugins: []Component{{ID: "mind", Pkg: "@kb-labs/mind", PostInstall: []Step{
+			{Name: "Build index", Run: []string{"kb", "mind", "index"}},
+			{Name: "", Run: nil},
+		}}},
+	}
+
+	got := make(map[string]bool)
+	for _, p := range Validate(m) {
+		got[p.Path] = true
+	}
+	for _, path := range []string{"$.plugins[0].postInstall[1].name", "$.plugins[0].postInstall[1].run"} {
+		if !got[path] {
+			t.Errorf("missing problem at %s; got %v", path, got)
+		}
+	}
+	if len(got) != 2 {
+		t.Errorf("Validate() reported %d problems, want 2: %v", len(got), got)
+	}
+}
+
+// TestValidateServiceSnippet verifies that config snippets are rejected on
+// services, which the scaffold writes as plain toggles.
+func TestValidateServiceSnippet(t *testing.T) {
+	m := &Manifest{
+		Version:  "1.0.0",
+		Core:     []Package{{Name: "@kb-labs/cli-bin"}},
+		Services: []Component{{ID: "rest", Pkg: "@kb-labs/rest-api", Config: ConfigSection{Snippet: []string{`"port": 5050`}}}},
+	}
+	problems := Validate(m)
+	if len(problems) != 1 || problems[0].Path != "$.services[0].config.snippet" {
+		t.Errorf("Validate() = %v, want one problem at $.services[0].config.snippet", problems)
+	}
+}
+
+// TestValidateMinInstallerVersion verifies that minInstallerVersion must be
+// a version.
+func TestValidateMinInstallerVersion(t *testing.T) {
+	m := &Manifest{Version: "1.0.0", Core: []Package{{Name: "@kb-labs/cli-bin"}}, MinInstallerVersion: "soon"}
+	problems := Validate(m)
+	if len(problems) != 1 || problems[0].Path != "$.minInstallerVersion" {
+		t.Errorf("Validate() = %v, want one problem at $.minInstallerVersion", problems)
+	}
+}
+
+// TestValidateDeprecations verifies that replacements must be current
+// components of the same section and that deprecated components can't be
+// defaults or preset members.
+func TestValidateDeprecations(t *testing.T) {
+	m := &Manifest{
+		Version: "1.0.0",
+		Core:    []Package{{Name: "@kb-labs/cli-bin"}},
+		Services: []Component{
+			{ID: "rest", Pkg: "@kb-labs/rest-api", Ports: []Port{{Port: 5050}}},
+			{ID: "rest-v1", Pkg: "@kb-labs/rest-v1", Deprecated: "renamed", ReplacedBy: "rest", Ports: []Port{{Port: 5050}}},
+		},
+		Plugins: []Component{
+			{ID: "commit", Pkg: "@kb-labs/commit"},
+			{ID: "commit-cli", Pkg: "@kb-labs/commit-cli", Deprecated: "renamed", ReplacedBy: "commit", Default: true},
+			{ID: "old", Pkg: "@kb-labs/old", Deprecated: "gone", ReplacedBy: "commit-cli"},
+			{ID: "svc", Pkg: "@kb-labs/svc", Deprecated: "moved", ReplacedBy: "rest"},
+			{ID: "quiet", Pkg: "@kb-labs/quiet", ReplacedBy: "commit"},
+		},
+		Presets: []Preset{{ID: "p", Plugins: []string{"commit-cli"}}},
+	}
+
+	got := make(map[string]bool)
+	for _, p := range Validate(m) {
+		got[p.Path] = true
+	}
+	want := []string{
+		"$.plugins[1].default",
+		"$.plugins[2].replacedBy", // replacement deprecated too
+		"$.plugins[3].replacedBy", // replacement is a service
+		"$.plugins[4].replacedBy", // not deprecated
+		"$.presets[0].plugins[0]",
+	}
+	for _, path := range want {
+		if !got[path] {
+			t.Errorf("missing problem at %s; got %v", path, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("Validate() problems = %v, want only %v", got, want)
+	}
+}
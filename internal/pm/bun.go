@@ -0,0 +1,120 @@
+package pm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// BunManager implements PackageManager using Bun, which needs no Node.js.
+type BunManager struct{}
+
+func (b *BunManager) Name() string { return "bun" }
+
+func (b *BunManager) Install(ctx context.Context, dir string, pkgs []string, progress chan<- Progress) error {
+	return run(ctx, dir, "bun", append([]string{"add"}, pkgs...), rawLines, progress)
+}
+
+func (b *BunManager) Update(ctx context.Context, dir string, pkgs []string, progress chan<- Progress) error {
+	return run(ctx, dir, "bun", append([]string{"update"}, pkgs...), rawLines, progress)
+}
+
+func (b *BunManager) Uninstall(ctx context.Context, dir string, pkgs []string, progress chan<- Progress) error {
+	return run(ctx, dir, "bun", append([]string{"remove"}, pkgs...), rawLines, progress)
+}
+
+// ListInstalled parses `bun pm ls`, filtered to the direct dependencies in
+// dir/package.json.
+func (b *BunManager) ListInstalled(ctx context.Context, dir string) ([]InstalledPackage, error) {
+	deps, err := directDependencies(dir)
+	if err != nil || len(deps) == 0 {
+		return nil, err
+	}
+
+	cmd := Command(ctx, dir, "bun", "pm", "ls")
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("bun pm ls: %w", err)
+	}
+
+	var pkgs []InstalledPackage
+	for _, p := range parseBunList(out) {
+		if _, ok := deps[p.Name]; ok {
+			pkgs = append(pkgs, p)
+		}
+	}
+	return pkgs, nil
+}
+
+// Outdated parses the table printed by `bun outdated`, filtered to pkgs.
+func (b *BunManager) Outdated(ctx context.Context, dir string, pkgs []string) ([]OutdatedPackage, error) {
+	cmd := Command(ctx, dir, "bun", "outdated")
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("bun outdated: %w", err)
+	}
+
+	var outdated []OutdatedPackage
+	for _, p := range parseBunOutdated(out) {
+		if slices.Contains(pkgs, p.Name) {
+			outdated = append(outdated, p)
+		}
+	}
+	return outdated, nil
+}
+
+// parseBunOutdated parses the table printed by `bun outdated`:
+//
+//	┌──────────────┬─────────┬────────┬────────┐
+//	│ Package      │ Current │ Update │ Latest │
+//	├──────────────┼─────────┼────────┼────────┤
+//	│ @kb-labs/sdk │ 1.2.0   │ 1.3.1  │ 2.0.0  │
+//	└──────────────┴─────────┴────────┴────────┘
+//
+// Dev dependencies carry a " (dev)" suffix after the name.
+func parseBunOutdated(out []byte) []OutdatedPackage {
+	var head []string
+	var pkgs []OutdatedPackage
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		s := strings.TrimSpace(string(line))
+		if !strings.HasPrefix(s, "│") {
+			continue
+		}
+		cells := strings.Split(strings.Trim(s, "│"), "│")
+		for i, c := range cells {
+			cells[i] = strings.TrimSpace(c)
+		}
+		if head == nil {
+			head = cells
+			continue
+		}
+		p := outdatedRow(head, cells, "Update")
+		p.Name, _, _ = strings.Cut(p.Name, " ")
+		pkgs = append(pkgs, p)
+	}
+	return pkgs
+}
+
+// parseBunList parses the tree printed by `bun pm ls`:
+//
+//	/path/to/platform node_modules (2)
+//	├── @kb-labs/sdk@1.2.3
+//	└── left-pad@1.3.0
+func parseBunList(out []byte) []InstalledPackage {
+	var pkgs []InstalledPackage
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		s := strings.TrimSpace(string(line))
+		entry, ok := strings.CutPrefix(s, "├── ")
+		if !ok {
+			entry, ok = strings.CutPrefix(s, "└── ")
+		}
+		if !ok {
+			continue
+		}
+		name, version := splitNameVersion(strings.TrimSpace(entry))
+		pkgs = append(pkgs, InstalledPackage{Name: name, Version: version})
+	}
+	return pkgs
+}
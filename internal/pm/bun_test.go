@@ -0,0 +1,126 @@
+package pm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// fakeBunScript prints a canned `bun pm ls` tree and logs every invocation's
+// arguments to $FAKE_PM_LOG.
+const fakeBunScript = `#!/bin/sh
+echo "$@" >> "$FAKE_PM_LOG"
+case "$1" in
+pm)
+  echo "/tmp/platform node_modules (3)"
+  echo "├── @kb-labs/sdk@1.4.0"
+  echo "├── kb-platform@workspace:."
+  echo "└── left-pad@1.3.0"
+  ;;
+outdated)
+  echo "bun outdated v1.1.38"
+  echo "┌────────────────────┬─────────┬────────┬────────┐"
+  echo "│ Package            │ Current │ Update │ Latest │"
+  echo "├────────────────────┼─────────┼────────┼────────┤"
+  echo "│ @kb-labs/sdk       │ 1.4.0   │ 1.5.2  │ 2.0.0  │"
+  echo "├────────────────────┼─────────┼────────┼────────┤"
+  echo "│ @types/node (dev)  │ 20.1.0  │ 20.2.0 │ 22.0.0 │"
+  echo "└────────────────────┴─────────┴────────┴────────┘"
+  ;;
+add) echo "installed $2" ;;
+esac
+`
+
+// TestBunManagerName verifies BunManager.Name returns "bun".
+func TestBunManagerName(t *testing.T) {
+	if got := (&BunManager{}).Name(); got != "bun" {
+		t.Errorf("BunManager.Name() = %q, want \"bun\"", got)
+	}
+}
+
+// TestBunCommands verifies the bun subcommands and that progress lines are
+// streamed.
+func TestBunCommands(t *testing.T) {
+	dir, calls := fakeTool(t, "bun", fakeBunScript)
+	b := &BunManager{}
+
+	ch := make(chan Progress, 16)
+	if err := b.Install(context.Background(), dir, []string{"@kb-labs/sdk@^1.0.0"}, ch); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if err := b.Update(context.Background(), dir, []string{"@kb-labs/sdk"}, ch); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := b.Uninstall(context.Background(), dir, []string{"@kb-labs/sdk"}, ch); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	close(ch)
+
+	if lines := drain(ch); !slices.Contains(lines, "installed @kb-labs/sdk@^1.0.0") {
+		t.Errorf("progress lines = %v, want install output", lines)
+	}
+	want := []string{"add @kb-labs/sdk@^1.0.0", "update @kb-labs/sdk", "remove @kb-labs/sdk"}
+	if got := calls(); !slices.Equal(got, want) {
+		t.Errorf("bun calls = %q, want %q", got, want)
+	}
+}
+
+// TestBunListInstalled verifies that the `bun pm ls` tree is parsed and
+// filtered to the platform's direct dependencies.
+func TestBunListInstalled(t *testing.T) {
+	dir, _ := fakeTool(t, "bun", fakeBunScript)
+	pkgs, err := (&BunManager{}).ListInstalled(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("ListInstalled() error = %v", err)
+	}
+	if len(pkgs) != 1 || pkgs[0].Name != "@kb-labs/sdk" || pkgs[0].Version != "1.4.0" {
+		t.Errorf("ListInstalled() = %+v, want @kb-labs/sdk 1.4.0", pkgs)
+	}
+}
+
+// TestBunOutdated verifies that the `bun outdated` table is parsed and
+// filtered to the requested packages.
+func TestBunOutdated(t *testing.T) {
+	dir, _ := fakeTool(t, "bun", fakeBunScript)
+	pkgs, err := (&BunManager{}).Outdated(context.Background(), dir, []string{"@kb-labs/sdk"})
+	if err != nil {
+		t.Fatalf("Outdated() error = %v", err)
+	}
+	want := []OutdatedPackage{{Name: "@kb-labs/sdk", Current: "1.4.0", Wanted: "1.5.2", Latest: "2.0.0"}}
+	if !slices.Equal(pkgs, want) {
+		t.Errorf("Outdated() = %+v, want %+v", pkgs, want)
+	}
+	if all := parseBunOutdated([]byte(fakeBunScript)); len(all) != 0 {
+		t.Errorf("parseBunOutdated() on non-table lines = %+v, want none", all)
+	}
+}
+
+// TestDetectPriority verifies the pnpm, yarn, bun, npm order and that bun
+// wins when Node.js is missing.
+func TestDetectPriority(t *testing.T) {
+	tests := []struct {
+		bins []string
+		want string
+	}{
+		{[]string{"node", "pnpm", "yarn", "bun"}, "pnpm"},
+		{[]string{"node", "yarn", "bun"}, "yarn"},
+		{[]string{"node", "bun"}, "bun"},
+		{[]string{"node"}, "npm"},
+		{[]string{"pnpm", "yarn", "bun"}, "bun"},
+	}
+	for _, tt := range tests {
+		bin := t.TempDir()
+		for _, name := range tt.bins {
+			// #nosec G306 -- the fake binaries must be executable.
+			if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		t.Setenv("PATH", bin)
+		if got := Detect().Name(); got != tt.want {
+			t.Errorf("Detect() with %v = %q, want %q", tt.bins, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package pm
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// killGrace is how long a cancelled process tree gets to exit after SIGTERM
+// before it is killed outright.
+const killGrace = 5 * time.Second
+
+// Cmd is an exec.Cmd started by Command. Its Wait, Run, Output and
+// CombinedOutput also stop the delayed kill of the process group that
+// cancellation scheduled, so it never fires at a group ID the system may
+// have handed out again.
+type Cmd struct {
+	*exec.Cmd
+	stopKill func()
+}
+
+// Command returns a command for name that runs in dir in its own process
+// group. Cancelling ctx terminates the whole group, including the install
+// scripts and workers package managers spawn, rather than only the direct
+// child.
+func Command(ctx context.Context, dir, name string, args ...string) *Cmd {
+	// #nosec G204 -- callers pass fixed binaries and internal arguments.
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	stopKill := setProcessGroup(cmd)
+	// Stop waiting for output pipes held open by stray grandchildren.
+	cmd.WaitDelay = killGrace + time.Second
+	return &Cmd{Cmd: cmd, stopKill: stopKill}
+}
+
+// Wait waits for the command like exec.Cmd.Wait.
+func (c *Cmd) Wait() error {
+	defer c.stopKill()
+	return c.Cmd.Wait()
+}
+
+// Run starts the command and waits for it like exec.Cmd.Run.
+func (c *Cmd) Run() error {
+	defer c.stopKill()
+	return c.Cmd.Run()
+}
+
+// Output runs the command and returns its stdout like exec.Cmd.Output.
+func (c *Cmd) Output() ([]byte, error) {
+	defer c.stopKill()
+	return c.Cmd.Output()
+}
+
+// CombinedOutput runs the command and returns its stdout and stderr like
+// exec.Cmd.CombinedOutput.
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	defer c.stopKill()
+	return c.Cmd.CombinedOutput()
+}
+
+// run executes bin with args in dir, streaming non-empty stdout and stderr
+// lines to progress after passing them through parse. If ctx is cancelled it
+// returns an error wrapping ctx.Err() once the process tree has exited.
+func run(ctx context.Context, dir, bin string, args []string, parse lineParser, progress chan<- Progress) error {
+	if err := ensurePackageJSON(dir); err != nil {
+		return err
+	}
+
+	cmd := Command(ctx, dir, bin, args...)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%s: %w", bin, err)
+	}
+
+	// stream both stdout and stderr as progress events
+	done := make(chan struct{}, 2)
+	pipe := func(r interface{ Read([]byte) (int, error) }) {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if p, ok := parse(line); ok {
+				progress <- p
+			}
+		}
+		done <- struct{}{}
+	}
+	go pipe(stdout)
+	go pipe(stderr)
+	<-done
+	<-done
+
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return fmt.Errorf("%s %s: %w", bin, args[0], ctx.Err())
+	}
+	return err
+}
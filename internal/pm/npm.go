@@ -1,38 +1,60 @@
 package pm
 
 import (
-	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
 )
 
 // NpmManager implements PackageManager using npm.
-type NpmManager struct{}
+type NpmManager struct {
+	// Cache, if set, replaces npm's cache directory (--cache). Offline
+	// bundles are built by installing into a fresh cache and shipping it.
+	Cache string
+	// Offline makes npm install from Cache alone (--offline).
+	Offline bool
+}
 
 func (n *NpmManager) Name() string { return "npm" }
 
-func (n *NpmManager) Install(dir string, pkgs []string, progress chan<- Progress) error {
-	return n.run(dir, append([]string{"install", "--prefix", dir}, pkgs...), progress)
+// Install and Update log registry requests (--loglevel=http) so that
+// parseNpmLine can report per-package progress.
+func (n *NpmManager) Install(ctx context.Context, dir string, pkgs []string, progress chan<- Progress) error {
+	args := append(n.args("install", dir, "--loglevel=http"), pkgs...)
+	return run(ctx, dir, "npm", args, parseNpmLine, progress)
+}
+
+func (n *NpmManager) Update(ctx context.Context, dir string, pkgs []string, progress chan<- Progress) error {
+	args := append(n.args("update", dir, "--loglevel=http"), pkgs...)
+	return run(ctx, dir, "npm", args, parseNpmLine, progress)
+}
+
+func (n *NpmManager) Uninstall(ctx context.Context, dir string, pkgs []string, progress chan<- Progress) error {
+	return run(ctx, dir, "npm", append(n.args("uninstall", dir), pkgs...), rawLines, progress)
 }
 
-func (n *NpmManager) Update(dir string, pkgs []string, progress chan<- Progress) error {
-	return n.run(dir, append([]string{"update", "--prefix", dir}, pkgs...), progress)
+// args returns the npm arguments for cmd in dir, followed by extra.
+func (n *NpmManager) args(cmd, dir string, extra ...string) []string {
+	args := []string{cmd, "--prefix", dir}
+	if n.Cache != "" {
+		args = append(args, "--cache", n.Cache)
+	}
+	if n.Offline {
+		args = append(args, "--offline")
+	}
+	return append(args, extra...)
 }
 
-func (n *NpmManager) ListInstalled(dir string) ([]InstalledPackage, error) {
+func (n *NpmManager) ListInstalled(ctx context.Context, dir string) ([]InstalledPackage, error) {
 	nmDir := filepath.Join(dir, "node_modules")
 	if _, err := os.Stat(nmDir); os.IsNotExist(err) {
 		return nil, nil
 	}
 
-	// #nosec G204 -- command name is fixed; dir is passed as an argument.
-	cmd := exec.CommandContext(context.Background(), "npm", "list", "--prefix", dir, "--json", "--depth=0")
-	cmd.Dir = dir
+	cmd := Command(ctx, dir, "npm", "list", "--prefix", dir, "--json", "--depth=0")
 	out, err := cmd.Output()
 	if err != nil && len(out) == 0 {
 		return nil, fmt.Errorf("npm list: %w", err)
@@ -54,46 +76,43 @@ func (n *NpmManager) ListInstalled(dir string) ([]InstalledPackage, error) {
 	return pkgs, nil
 }
 
-func (n *NpmManager) run(dir string, args []string, progress chan<- Progress) error {
-	if err := ensurePackageJSON(dir); err != nil {
-		return err
+// Outdated runs `npm outdated --json`, which exits 1 when anything is
+// outdated.
+func (n *NpmManager) Outdated(ctx context.Context, dir string, pkgs []string) ([]OutdatedPackage, error) {
+	cmd := Command(ctx, dir, "npm", append(n.args("outdated", dir, "--json"), pkgs...)...)
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("npm outdated: %w", err)
 	}
+	return parseOutdatedJSON("npm", out)
+}
 
-	// #nosec G204 -- command name is fixed; args are internal package names/options.
-	cmd := exec.CommandContext(context.Background(), "npm", args...)
-	cmd.Dir = dir
-
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return err
+// parseOutdatedJSON parses the object `npm outdated --json` and
+// `pnpm outdated --format=json` print, keyed by package name. npm reports
+// failures as an "error" object in the same output.
+func parseOutdatedJSON(bin string, out []byte) ([]OutdatedPackage, error) {
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil, nil
 	}
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return err
+	var result map[string]struct {
+		Current string `json:"current"`
+		Wanted  string `json:"wanted"`
+		Latest  string `json:"latest"`
+		Code    string `json:"code"`
+		Summary string `json:"summary"`
 	}
-
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("npm: %w", err)
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("parse %s outdated: %w", bin, err)
 	}
-
-	// stream both stdout and stderr as progress lines
-	done := make(chan struct{}, 2)
-	pipe := func(r interface{ Read([]byte) (int, error) }) {
-		scanner := bufio.NewScanner(r)
-		for scanner.Scan() {
-			line := scanner.Text()
-			if strings.TrimSpace(line) != "" {
-				progress <- Progress{Line: line}
-			}
-		}
-		done <- struct{}{}
+	if e, ok := result["error"]; ok && e.Code != "" {
+		return nil, fmt.Errorf("%s outdated: %s: %s", bin, e.Code, e.Summary)
 	}
-	go pipe(stdout)
-	go pipe(stderr)
-	<-done
-	<-done
 
-	return cmd.Wait()
+	pkgs := make([]OutdatedPackage, 0, len(result))
+	for name, dep := range result {
+		pkgs = append(pkgs, OutdatedPackage{Name: name, Current: dep.Current, Wanted: dep.Wanted, Latest: dep.Latest})
+	}
+	return pkgs, nil
 }
 
 // ensurePackageJSON creates a minimal package.json if none exists.
@@ -3,41 +3,127 @@
 package pm
 
 import (
+	"context"
+	"fmt"
 	"os/exec"
+	"strings"
+	"time"
 )
 
-// Progress reports installation progress for a single step.
+// Progress reports installation progress for a single step. Managers with
+// machine-readable output (npm, pnpm) send per-package events with Package
+// and Phase set; other output arrives as plain lines.
 type Progress struct {
 	Error   error
-	Package string
-	Line    string // raw output line for logging
-	Done    bool
+	Package string // "name@version" or "name" for per-package events
+	Phase   Phase
+	Line    string // raw output line for logging; empty for package events
+	Done    bool   // the package reached the manager's last reported phase
 }
 
+// Phase is the stage a package has reached.
+type Phase string
+
+const (
+	PhaseResolved Phase = "resolved" // version picked from the registry
+	PhaseFetched  Phase = "fetched"  // tarball downloaded or found in a cache
+	PhaseLinked   Phase = "linked"   // placed into node_modules
+)
+
 // InstalledPackage describes a package found in node_modules.
 type InstalledPackage struct {
 	Name    string
 	Version string
 }
 
-// PackageManager abstracts npm/pnpm/bun install operations.
+// OutdatedPackage is an installed package with a newer version published.
+type OutdatedPackage struct {
+	Name    string
+	Current string // version in node_modules
+	Wanted  string // newest version in the package.json range; what Update installs
+	Latest  string // newest version on the registry
+}
+
+// PackageManager abstracts npm/pnpm/yarn/bun install operations.
 // All methods run synchronously and stream progress via the channel.
-// The channel is closed when the operation completes.
+// The channel is closed when the operation completes. Cancelling ctx stops
+// the package manager and everything it spawned.
 type PackageManager interface {
-	// Name returns "npm" or "pnpm".
+	// Name returns "npm", "pnpm", "yarn" or "bun".
 	Name() string
 	// Install installs the given packages into dir/node_modules.
-	Install(dir string, pkgs []string, progress chan<- Progress) error
+	Install(ctx context.Context, dir string, pkgs []string, progress chan<- Progress) error
 	// Update updates already-installed packages to their latest versions.
-	Update(dir string, pkgs []string, progress chan<- Progress) error
+	Update(ctx context.Context, dir string, pkgs []string, progress chan<- Progress) error
+	// Uninstall removes the given packages (names, no versions) from dir.
+	Uninstall(ctx context.Context, dir string, pkgs []string, progress chan<- Progress) error
 	// ListInstalled returns packages installed in dir.
-	ListInstalled(dir string) ([]InstalledPackage, error)
+	ListInstalled(ctx context.Context, dir string) ([]InstalledPackage, error)
+	// Outdated asks the registry which of the given installed packages
+	// (names, no versions) have newer versions. Up-to-date packages are
+	// left out.
+	Outdated(ctx context.Context, dir string, pkgs []string) ([]OutdatedPackage, error)
 }
 
-// Detect returns pnpm if available, otherwise npm.
-func Detect() PackageManager {
-	if _, err := exec.LookPath("pnpm"); err == nil {
+// Names lists the supported package managers in detection order.
+var Names = []string{"pnpm", "yarn", "bun", "npm"}
+
+// newManager returns the manager called name, or nil if name is unknown.
+func newManager(name string) PackageManager {
+	switch name {
+	case "npm":
+		return &NpmManager{}
+	case "pnpm":
 		return &PnpmManager{}
+	case "yarn":
+		return &YarnManager{}
+	case "bun":
+		return &BunManager{}
+	}
+	return nil
+}
+
+// New returns the package manager called name. It fails if name is not one
+// of Names or its binary is not on PATH.
+func New(name string) (PackageManager, error) {
+	m := newManager(name)
+	if m == nil {
+		return nil, fmt.Errorf("unknown package manager %q (want %s)", name, strings.Join(Names, ", "))
+	}
+	if !onPath(name) {
+		return nil, fmt.Errorf("%s is not installed or not on PATH", name)
+	}
+	return m, nil
+}
+
+// Detect returns the first package manager found on PATH in the order of
+// Names. Without Node.js on PATH only bun can install packages, so it is
+// picked first then.
+func Detect() PackageManager {
+	if !onPath("node") && onPath("bun") {
+		return &BunManager{}
+	}
+	for _, name := range Names {
+		if onPath(name) {
+			return newManager(name)
+		}
 	}
 	return &NpmManager{}
 }
+
+func onPath(bin string) bool {
+	_, err := exec.LookPath(bin)
+	return err == nil
+}
+
+// NodeVersion returns the output of `node --version` without the leading "v",
+// or "" if node is not installed or fails to run.
+func NodeVersion() string {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "node", "--version").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(out)), "v")
+}
@@ -3,6 +3,7 @@ package pm
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -17,12 +18,13 @@ func TestDetectReturnsNonNil(t *testing.T) {
 	}
 }
 
-// TestDetectNameIsKnown verifies the detected manager name is either "npm" or "pnpm".
+// TestDetectNameIsKnown verifies the detected manager name is a known one.
 func TestDetectNameIsKnown(t *testing.T) {
 	mgr := Detect()
-	name := mgr.Name()
-	if name != "npm" && name != "pnpm" {
-		t.Errorf("Detect() name = %q, want \"npm\" or \"pnpm\"", name)
+	switch name := mgr.Name(); name {
+	case "npm", "pnpm", "yarn", "bun":
+	default:
+		t.Errorf("Detect() name = %q, want npm, pnpm, yarn or bun", name)
 	}
 }
 
@@ -34,6 +36,45 @@ func TestNpmManagerName(t *testing.T) {
 	}
 }
 
+// TestNpmManagerCacheArgs verifies the --cache and --offline arguments used
+// for offline bundles.
+func TestNpmManagerCacheArgs(t *testing.T) {
+	n := &NpmManager{Cache: "/tmp/cache", Offline: true}
+	got := strings.Join(n.args("install", "/p", "--loglevel=http"), " ")
+	want := "install --prefix /p --cache /tmp/cache --offline --loglevel=http"
+	if got != want {
+		t.Errorf("args() = %q, want %q", got, want)
+	}
+	if got := strings.Join((&NpmManager{}).args("uninstall", "/p"), " "); got != "uninstall --prefix /p" {
+		t.Errorf("args() = %q, want no cache arguments", got)
+	}
+}
+
+// TestParseOutdatedJSON verifies the npm/pnpm outdated JSON, including the
+// empty output of an up-to-date platform and npm's error object.
+func TestParseOutdatedJSON(t *testing.T) {
+	out := `{"@kb-labs/mind":{"current":"1.2.0","wanted":"1.3.1","latest":"2.0.0","dependent":"kb-platform"}}`
+	pkgs, err := parseOutdatedJSON("npm", []byte(out))
+	if err != nil {
+		t.Fatalf("parseOutdatedJSON() error = %v", err)
+	}
+	want := OutdatedPackage{Name: "@kb-labs/mind", Current: "1.2.0", Wanted: "1.3.1", Latest: "2.0.0"}
+	if len(pkgs) != 1 || pkgs[0] != want {
+		t.Errorf("parseOutdatedJSON() = %+v, want %+v", pkgs, want)
+	}
+
+	for _, out := range []string{"", "{}\n"} {
+		if pkgs, err := parseOutdatedJSON("pnpm", []byte(out)); err != nil || len(pkgs) != 0 {
+			t.Errorf("parseOutdatedJSON(%q) = %+v, %v, want nothing", out, pkgs, err)
+		}
+	}
+
+	out = `{"error":{"code":"ENOTFOUND","summary":"request to https://registry.npmjs.org failed"}}`
+	if _, err := parseOutdatedJSON("npm", []byte(out)); err == nil || !strings.Contains(err.Error(), "ENOTFOUND") {
+		t.Errorf("parseOutdatedJSON() error = %v, want ENOTFOUND", err)
+	}
+}
+
 // TestPnpmManagerName verifies PnpmManager.Name returns "pnpm".
 func TestPnpmManagerName(t *testing.T) {
 	p := &PnpmManager{}
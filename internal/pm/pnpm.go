@@ -1,34 +1,34 @@
 package pm
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
-	"path/filepath"
-	"strings"
 )
 
-// PnpmManager implements PackageManager using pnpm.
+// PnpmManager implements PackageManager using pnpm. Commands use the ndjson
+// reporter so that parsePnpmLine can report per-package progress.
 type PnpmManager struct{}
 
 func (p *PnpmManager) Name() string { return "pnpm" }
 
-func (p *PnpmManager) Install(dir string, pkgs []string, progress chan<- Progress) error {
-	args := append([]string{"add", "--dir", dir}, pkgs...)
-	return p.run(dir, args, progress)
+func (p *PnpmManager) Install(ctx context.Context, dir string, pkgs []string, progress chan<- Progress) error {
+	args := append([]string{"add", "--dir", dir, "--reporter=ndjson"}, pkgs...)
+	return run(ctx, dir, "pnpm", args, parsePnpmLine, progress)
 }
 
-func (p *PnpmManager) Update(dir string, pkgs []string, progress chan<- Progress) error {
-	args := append([]string{"update", "--dir", dir}, pkgs...)
-	return p.run(dir, args, progress)
+func (p *PnpmManager) Update(ctx context.Context, dir string, pkgs []string, progress chan<- Progress) error {
+	args := append([]string{"update", "--dir", dir, "--reporter=ndjson"}, pkgs...)
+	return run(ctx, dir, "pnpm", args, parsePnpmLine, progress)
 }
 
-func (p *PnpmManager) ListInstalled(dir string) ([]InstalledPackage, error) {
-	// #nosec G204 -- command name is fixed; dir is passed as an argument.
-	cmd := exec.CommandContext(context.Background(), "pnpm", "list", "--dir", dir, "--json", "--depth=0")
-	cmd.Dir = dir
+func (p *PnpmManager) Uninstall(ctx context.Context, dir string, pkgs []string, progress chan<- Progress) error {
+	args := append([]string{"remove", "--dir", dir, "--reporter=ndjson"}, pkgs...)
+	return run(ctx, dir, "pnpm", args, parsePnpmLine, progress)
+}
+
+func (p *PnpmManager) ListInstalled(ctx context.Context, dir string) ([]InstalledPackage, error) {
+	cmd := Command(ctx, dir, "pnpm", "list", "--dir", dir, "--json", "--depth=0")
 	out, err := cmd.Output()
 	if err != nil && len(out) == 0 {
 		return nil, fmt.Errorf("pnpm list: %w", err)
@@ -56,46 +56,13 @@ func (p *PnpmManager) ListInstalled(dir string) ([]InstalledPackage, error) {
 	return pkgList, nil
 }
 
-func (p *PnpmManager) run(dir string, args []string, progress chan<- Progress) error {
-	if err := ensurePackageJSON(dir); err != nil {
-		return err
-	}
-	// ensure pnpm-workspace is NOT present (we want flat install, not workspace)
-	wsPath := filepath.Join(dir, "pnpm-workspace.yaml")
-	_ = wsPath // intentionally not creating it
-
-	// #nosec G204 -- command name is fixed; args are internal package names/options.
-	cmd := exec.CommandContext(context.Background(), "pnpm", args...)
-	cmd.Dir = dir
-
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return err
-	}
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return err
-	}
-
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("pnpm: %w", err)
-	}
-
-	done := make(chan struct{}, 2)
-	pipe := func(r interface{ Read([]byte) (int, error) }) {
-		scanner := bufio.NewScanner(r)
-		for scanner.Scan() {
-			line := scanner.Text()
-			if strings.TrimSpace(line) != "" {
-				progress <- Progress{Line: line}
-			}
-		}
-		done <- struct{}{}
+// Outdated runs `pnpm outdated`, which exits 1 when anything is outdated.
+func (p *PnpmManager) Outdated(ctx context.Context, dir string, pkgs []string) ([]OutdatedPackage, error) {
+	args := append([]string{"outdated", "--dir", dir, "--format=json"}, pkgs...)
+	cmd := Command(ctx, dir, "pnpm", args...)
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("pnpm outdated: %w", err)
 	}
-	go pipe(stdout)
-	go pipe(stderr)
-	<-done
-	<-done
-
-	return cmd.Wait()
+	return parseOutdatedJSON("pnpm", out)
 }
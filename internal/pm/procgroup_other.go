@@ -0,0 +1,9 @@
+//go:build !unix
+
+package pm
+
+import "os/exec"
+
+// setProcessGroup is a no-op where process groups aren't available;
+// cancellation kills only the direct child.
+func setProcessGroup(cmd *exec.Cmd) (stopKill func()) { return func() {} }
@@ -0,0 +1,36 @@
+//go:build unix
+
+package pm
+
+import (
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// setProcessGroup starts cmd as the leader of a new process group and makes
+// cancellation signal the whole group: SIGTERM first, SIGKILL after killGrace
+// for anything still running. stopKill cancels a pending SIGKILL and is
+// called once Wait returns.
+func setProcessGroup(cmd *exec.Cmd) (stopKill func()) {
+	var (
+		mu   sync.Mutex
+		kill *time.Timer
+	)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		pgid := -cmd.Process.Pid
+		mu.Lock()
+		kill = time.AfterFunc(killGrace, func() { _ = syscall.Kill(pgid, syscall.SIGKILL) })
+		mu.Unlock()
+		return syscall.Kill(pgid, syscall.SIGTERM)
+	}
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if kill != nil {
+			kill.Stop()
+		}
+	}
+}
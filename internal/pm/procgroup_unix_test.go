@@ -0,0 +1,78 @@
+//go:build unix
+
+package pm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestRunCancelKillsProcessGroup verifies that cancelling ctx stops the
+// package manager and the processes it spawned, and reports ctx.Err().
+func TestRunCancelKillsProcessGroup(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	t.Setenv("CHILD_PID_FILE", pidFile)
+	script := "#!/bin/sh\n/bin/sleep 30 &\necho $! > \"$CHILD_PID_FILE\"\necho started\nwait\n"
+	dir, _ := fakeTool(t, "npm", script)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan Progress, 16)
+	errc := make(chan error, 1)
+	go func() { errc <- (&NpmManager{}).Install(ctx, dir, []string{"x"}, ch) }()
+
+	// Wait for the script to start its child before cancelling.
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake npm did not start")
+	}
+	cancel()
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Install() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(killGrace + 2*time.Second):
+		t.Fatal("Install() did not return after cancel")
+	}
+
+	// #nosec G304 -- reads a file written under the test's temp dir.
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for running(pid) {
+		if time.Now().After(deadline) {
+			t.Fatalf("child process %d still running after cancel", pid)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// running reports whether pid is alive. Zombies count as exited: an orphaned
+// child may wait a while for PID 1 to reap it.
+func running(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	// #nosec G304 -- /proc path built from a numeric pid.
+	stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return true // no procfs (macOS): trust kill
+	}
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) == 0 || fields[0] != "Z"
+}
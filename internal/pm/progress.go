@@ -0,0 +1,138 @@
+package pm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// lineParser turns one line of package manager output into a Progress.
+// It returns false for lines that carry nothing worth reporting.
+type lineParser func(line string) (Progress, bool)
+
+// rawLines reports every line as is.
+func rawLines(line string) (Progress, bool) { return Progress{Line: line}, true }
+
+// parsePnpmLine parses pnpm's `--reporter=ndjson` output. Progress events
+// become package events: resolved, fetched (downloaded or found in the
+// store) and linked (imported into node_modules), which is the last phase.
+// Log messages and summaries become plain lines.
+func parsePnpmLine(line string) (Progress, bool) {
+	var ev struct {
+		Name      string `json:"name"`
+		Status    string `json:"status"`
+		PackageID string `json:"packageId"`
+		Message   any    `json:"message"`
+		Added     any    `json:"added"`
+		Removed   any    `json:"removed"`
+	}
+	if json.Unmarshal([]byte(line), &ev) != nil || ev.Name == "" {
+		return rawLines(line)
+	}
+
+	switch ev.Name {
+	case "pnpm:progress":
+		pkg := pnpmPackage(ev.PackageID)
+		switch ev.Status {
+		case "resolved":
+			return Progress{Package: pkg, Phase: PhaseResolved}, true
+		case "fetched", "found_in_store":
+			return Progress{Package: pkg, Phase: PhaseFetched}, true
+		case "imported":
+			return Progress{Package: pkg, Phase: PhaseLinked, Done: true}, true
+		}
+		return Progress{}, false
+	case "pnpm:root":
+		// {"added": {"name": "x", "version": "1.0.0"}} for each direct dependency.
+		if added, ok := ev.Added.(map[string]any); ok {
+			return Progress{Line: fmt.Sprintf("+ %v %v", added["name"], added["version"])}, true
+		}
+		if removed, ok := ev.Removed.(map[string]any); ok {
+			return Progress{Line: fmt.Sprintf("- %v", removed["name"])}, true
+		}
+		return Progress{}, false
+	case "pnpm:stats":
+		if n, ok := ev.Added.(float64); ok && n > 0 {
+			return Progress{Line: fmt.Sprintf("added %d packages", int(n))}, true
+		}
+		if n, ok := ev.Removed.(float64); ok && n > 0 {
+			return Progress{Line: fmt.Sprintf("removed %d packages", int(n))}, true
+		}
+		return Progress{}, false
+	}
+	if msg, ok := ev.Message.(string); ok && strings.TrimSpace(msg) != "" {
+		return Progress{Line: msg}, true
+	}
+	return Progress{}, false
+}
+
+// pnpmPackage turns a pnpm package ID into "name@version". IDs look like
+// "registry.npmjs.org/@scope/name/1.2.3" in older pnpm versions and
+// "@scope/name@1.2.3" in newer ones.
+func pnpmPackage(id string) string {
+	id = strings.TrimPrefix(id, "/")
+	if i := strings.LastIndex(id, "@"); i > 0 && !strings.Contains(id[i:], "/") {
+		return id
+	}
+	segs := strings.Split(id, "/")
+	if len(segs) < 3 {
+		return id
+	}
+	name, version := segs[len(segs)-2], segs[len(segs)-1]
+	if scope := segs[len(segs)-3]; strings.HasPrefix(scope, "@") {
+		name = scope + "/" + name
+	}
+	return name + "@" + version
+}
+
+// parseNpmLine parses npm's `--loglevel=http` output, where every registry
+// request is logged as
+//
+//	npm http fetch GET 200 https://registry.npmjs.org/left-pad 41ms (cache miss)
+//
+// A metadata request means the package is being resolved; a tarball request
+// (".../-/left-pad-1.3.0.tgz") means it was fetched, which is the last phase
+// npm reports. Other requests under "/-/", such as the audit POST to
+// "/-/npm/v1/security/advisories/bulk", are registry API calls, not
+// packages, and are dropped. Other lines are passed through.
+func parseNpmLine(line string) (Progress, bool) {
+	rest, ok := strings.CutPrefix(line, "npm http fetch ")
+	if !ok {
+		return rawLines(line)
+	}
+	fields := strings.Fields(rest) // GET 200 <url> 41ms (cache miss)
+	if len(fields) < 3 || fields[0] != "GET" {
+		return Progress{}, false
+	}
+	u, err := url.Parse(fields[2])
+	if err != nil {
+		return Progress{}, false
+	}
+	p, err := url.PathUnescape(u.EscapedPath())
+	if err != nil {
+		return Progress{}, false
+	}
+
+	if dir, file, ok := strings.Cut(p, "/-/"); ok {
+		if !strings.HasSuffix(file, ".tgz") || strings.Trim(dir, "/") == "" {
+			return Progress{}, false
+		}
+		name := npmName(dir)
+		version := strings.TrimSuffix(strings.TrimPrefix(file, path.Base(name)+"-"), ".tgz")
+		return Progress{Package: name + "@" + version, Phase: PhaseFetched, Done: true}, true
+	}
+	return Progress{Package: npmName(p), Phase: PhaseResolved}, true
+}
+
+// npmName returns the package name at the end of a registry URL path,
+// which may carry a registry-specific prefix.
+func npmName(p string) string {
+	segs := strings.Split(strings.Trim(p, "/"), "/")
+	name := segs[len(segs)-1]
+	if len(segs) > 1 && strings.HasPrefix(segs[len(segs)-2], "@") {
+		name = segs[len(segs)-2] + "/" + name
+	}
+	return name
+}
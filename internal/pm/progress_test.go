@@ -0,0 +1,121 @@
+package pm
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// ── pnpm ──────────────────────────────────────────────────────────────────────
+
+// TestParsePnpmLine verifies that pnpm ndjson events become package events
+// and readable log lines.
+func TestParsePnpmLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want Progress
+		ok   bool
+	}{
+		{
+			`{"name":"pnpm:progress","status":"resolved","packageId":"registry.npmjs.org/@kb-labs/sdk/1.2.3","requester":"/p"}`,
+			Progress{Package: "@kb-labs/sdk@1.2.3", Phase: PhaseResolved}, true,
+		},
+		{
+			`{"name":"pnpm:progress","status":"found_in_store","packageId":"left-pad@1.3.0"}`,
+			Progress{Package: "left-pad@1.3.0", Phase: PhaseFetched}, true,
+		},
+		{
+			`{"name":"pnpm:progress","status":"imported","packageId":"/@kb-labs/sdk@1.2.3","method":"hardlink"}`,
+			Progress{Package: "@kb-labs/sdk@1.2.3", Phase: PhaseLinked, Done: true}, true,
+		},
+		{`{"name":"pnpm:progress","status":"fetching_started","packageId":"left-pad@1.3.0"}`, Progress{}, false},
+		{`{"name":"pnpm:root","added":{"name":"left-pad","version":"1.3.0"}}`, Progress{Line: "+ left-pad 1.3.0"}, true},
+		{`{"name":"pnpm:stats","added":37,"prefix":"/p"}`, Progress{Line: "added 37 packages"}, true},
+		{`{"name":"pnpm","level":"warn","message":"deprecated subdependency"}`, Progress{Line: "deprecated subdependency"}, true},
+		{`{"name":"pnpm:summary","prefix":"/p"}`, Progress{}, false},
+		{"Progress: resolved 1, reused 0", Progress{Line: "Progress: resolved 1, reused 0"}, true},
+	}
+	for _, tt := range tests {
+		got, ok := parsePnpmLine(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parsePnpmLine(%s) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// ── npm ───────────────────────────────────────────────────────────────────────
+
+// TestParseNpmLine verifies that npm http log lines become package events.
+func TestParseNpmLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want Progress
+		ok   bool
+	}{
+		{
+			"npm http fetch GET 200 https://registry.npmjs.org/left-pad 41ms (cache miss)",
+			Progress{Package: "left-pad", Phase: PhaseResolved}, true,
+		},
+		{
+			"npm http fetch GET 200 https://registry.npmjs.org/@kb-labs%2fsdk 12ms (cache revalidated)",
+			Progress{Package: "@kb-labs/sdk", Phase: PhaseResolved}, true,
+		},
+		{
+			"npm http fetch GET 200 https://registry.npmjs.org/@kb-labs/sdk/-/sdk-1.2.3.tgz 80ms (cache miss)",
+			Progress{Package: "@kb-labs/sdk@1.2.3", Phase: PhaseFetched, Done: true}, true,
+		},
+		{
+			"npm http fetch GET 200 https://npm.example.com/repository/npm/left-pad/-/left-pad-1.3.0.tgz 5ms",
+			Progress{Package: "left-pad@1.3.0", Phase: PhaseFetched, Done: true}, true,
+		},
+		{"npm http fetch POST 200 https://registry.npmjs.org/-/npm/v1/security/advisories/bulk 310ms", Progress{}, false},
+		{"npm http fetch GET 200 https://registry.npmjs.org/-/v1/search?text=kb-labs 90ms", Progress{}, false},
+		{"npm http fetch GET 200 https://npm.example.com/repository/npm/-/npm/v1/security/audits/quick 20ms", Progress{}, false},
+		{"npm http fetch GET", Progress{}, false},
+		{"added 37 packages in 3s", Progress{Line: "added 37 packages in 3s"}, true},
+	}
+	for _, tt := range tests {
+		got, ok := parseNpmLine(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseNpmLine(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// ── run ───────────────────────────────────────────────────────────────────────
+
+// fakePnpmScript prints a short ndjson install log on stderr, as pnpm does.
+const fakePnpmScript = `#!/bin/sh
+echo "$@" >> "$FAKE_PM_LOG"
+echo '{"name":"pnpm:progress","status":"resolved","packageId":"left-pad@1.3.0"}' >&2
+echo '{"name":"pnpm:progress","status":"imported","packageId":"left-pad@1.3.0"}' >&2
+echo '{"name":"pnpm:stats","added":2}' >&2
+`
+
+// TestPnpmInstallReportsPackages verifies that pnpm runs with the ndjson
+// reporter and its output arrives as package events.
+func TestPnpmInstallReportsPackages(t *testing.T) {
+	dir, calls := fakeTool(t, "pnpm", fakePnpmScript)
+
+	ch := make(chan Progress, 16)
+	if err := (&PnpmManager{}).Install(context.Background(), dir, []string{"left-pad"}, ch); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	close(ch)
+
+	var got []Progress
+	for p := range ch {
+		got = append(got, p)
+	}
+	want := []Progress{
+		{Package: "left-pad@1.3.0", Phase: PhaseResolved},
+		{Package: "left-pad@1.3.0", Phase: PhaseLinked, Done: true},
+		{Line: "added 2 packages"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("progress = %+v, want %+v", got, want)
+	}
+	if c := calls(); len(c) != 1 || c[0] != "add --dir "+dir+" --reporter=ndjson left-pad" {
+		t.Errorf("pnpm calls = %q", c)
+	}
+}
@@ -0,0 +1,249 @@
+package pm
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TokenEnv holds the auth token for the default registry. The token for a
+// scope's registry is read from TokenEnv + "_" + the scope in upper case
+// ("@corp-int" → KB_NPM_TOKEN_CORP_INT); see ScopeTokenEnv.
+const TokenEnv = "KB_NPM_TOKEN"
+
+// npmrcHeader marks the .npmrc as generated.
+const npmrcHeader = "# Written by kb-create from the manifest registry settings; edits are overwritten.\n"
+
+// Registry says where a platform's packages are installed from.
+type Registry struct {
+	URL    string            // default registry; empty keeps the manager's own default
+	Scopes map[string]string // npm scope ("@corp") → registry URL
+	Tokens map[string]string // registry URL → auth token
+}
+
+// ScopeTokenEnv returns the environment variable holding the auth token for
+// scope's registry.
+func ScopeTokenEnv(scope string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, strings.TrimPrefix(scope, "@"))
+	return TokenEnv + "_" + name
+}
+
+// Secrets returns the auth tokens in r, for redaction from logs.
+func (r Registry) Secrets() []string {
+	var secrets []string
+	for _, t := range r.Tokens {
+		if t != "" {
+			secrets = append(secrets, t)
+		}
+	}
+	sort.Strings(secrets)
+	return secrets
+}
+
+// IsZero reports whether r configures nothing.
+func (r Registry) IsZero() bool {
+	return r.URL == "" && len(r.Scopes) == 0 && len(r.Tokens) == 0
+}
+
+// token returns the auth token for registry u.
+func (r Registry) token(u string) string {
+	if t := r.Tokens[u]; t != "" {
+		return t
+	}
+	for k, t := range r.Tokens {
+		if nerfDart(k) == nerfDart(u) {
+			return t
+		}
+	}
+	return ""
+}
+
+// ConfigureRegistry writes the registry settings for the platform in dir to
+// dir/.npmrc, which npm, pnpm, Yarn classic and Bun read for every command
+// run there; Yarn Berry gets the same settings through .yarnrc.yml.
+//
+// An empty u or nil scopes keeps the settings already in the file. Auth
+// tokens come from TokenEnv and ScopeTokenEnv, falling back to the token
+// already stored for the same registry, so later runs don't need them in
+// the environment. The file holds tokens and is written with 0600
+// permissions. The returned Registry is what the file now contains.
+func ConfigureRegistry(dir, u string, scopes map[string]string) (Registry, error) {
+	prev, err := readNpmrc(dir)
+	if err != nil {
+		return Registry{}, err
+	}
+
+	r := Registry{URL: u, Scopes: scopes, Tokens: map[string]string{}}
+	if r.URL == "" {
+		r.URL = prev.URL
+	}
+	if r.Scopes == nil {
+		r.Scopes = prev.Scopes
+	}
+	addToken := func(reg, env string) {
+		if reg == "" {
+			return
+		}
+		if t := strings.TrimSpace(os.Getenv(env)); t != "" {
+			r.Tokens[reg] = t
+		} else if r.token(reg) == "" {
+			if t := prev.token(reg); t != "" {
+				r.Tokens[reg] = t
+			}
+		}
+	}
+	for scope, reg := range r.Scopes {
+		addToken(reg, ScopeTokenEnv(scope))
+	}
+	addToken(r.URL, TokenEnv)
+	if len(r.Tokens) == 0 {
+		r.Tokens = nil
+	}
+
+	if r.IsZero() {
+		return r, nil
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return Registry{}, err
+	}
+	path := filepath.Join(dir, ".npmrc")
+	if err := os.WriteFile(path, r.npmrc(), 0o600); err != nil {
+		return Registry{}, err
+	}
+	// WriteFile keeps the mode of an existing file.
+	if err := os.Chmod(path, 0o600); err != nil {
+		return Registry{}, err
+	}
+	return r, nil
+}
+
+// npmrc renders r in .npmrc syntax.
+func (r Registry) npmrc() []byte {
+	var b bytes.Buffer
+	b.WriteString(npmrcHeader)
+	if r.URL != "" {
+		fmt.Fprintf(&b, "registry=%s\n", r.URL)
+	}
+	for _, scope := range sortedKeys(r.Scopes) {
+		fmt.Fprintf(&b, "%s:registry=%s\n", scope, r.Scopes[scope])
+	}
+	for _, reg := range sortedKeys(r.Tokens) {
+		fmt.Fprintf(&b, "%s:_authToken=%s\n", nerfDart(reg), r.Tokens[reg])
+	}
+	return b.Bytes()
+}
+
+// yarnrc renders r as Yarn Berry settings.
+func (r Registry) yarnrc() string {
+	var b strings.Builder
+	if r.URL != "" {
+		fmt.Fprintf(&b, "npmRegistryServer: %q\n", r.URL)
+		if t := r.token(r.URL); t != "" {
+			fmt.Fprintf(&b, "npmAuthToken: %q\n", t)
+		}
+	}
+	if len(r.Scopes) > 0 {
+		b.WriteString("npmScopes:\n")
+		for _, scope := range sortedKeys(r.Scopes) {
+			reg := r.Scopes[scope]
+			fmt.Fprintf(&b, "  %s:\n    npmRegistryServer: %q\n", strings.TrimPrefix(scope, "@"), reg)
+			if t := r.token(reg); t != "" {
+				fmt.Fprintf(&b, "    npmAuthToken: %q\n", t)
+			}
+		}
+	}
+	return b.String()
+}
+
+// readNpmrc parses the settings ConfigureRegistry writes to dir/.npmrc.
+// Tokens are keyed by registry URL where a registry line names it, else by
+// an https URL built from the token's host and path.
+func readNpmrc(dir string) (Registry, error) {
+	// #nosec G304 -- dir is the platform directory managed by kb-create.
+	data, err := os.ReadFile(filepath.Join(dir, ".npmrc"))
+	if os.IsNotExist(err) {
+		return Registry{}, nil
+	}
+	if err != nil {
+		return Registry{}, err
+	}
+
+	var r Registry
+	tokens := map[string]string{} // nerf dart → token
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		switch {
+		case key == "registry":
+			r.URL = val
+		case strings.HasPrefix(key, "@") && strings.HasSuffix(key, ":registry"):
+			if r.Scopes == nil {
+				r.Scopes = map[string]string{}
+			}
+			r.Scopes[strings.TrimSuffix(key, ":registry")] = val
+		case strings.HasPrefix(key, "//") && strings.HasSuffix(key, ":_authToken"):
+			tokens[strings.TrimSuffix(key, ":_authToken")] = val
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return Registry{}, fmt.Errorf("read .npmrc: %w", err)
+	}
+
+	regs := []string{r.URL}
+	for _, reg := range r.Scopes {
+		regs = append(regs, reg)
+	}
+	for dart, t := range tokens {
+		if r.Tokens == nil {
+			r.Tokens = map[string]string{}
+		}
+		key := "https:" + dart
+		for _, reg := range regs {
+			if reg != "" && nerfDart(reg) == dart {
+				key = reg
+			}
+		}
+		r.Tokens[key] = t
+	}
+	return r, nil
+}
+
+// nerfDart returns the scheme-less form of registry URL u that .npmrc uses
+// to key credentials: "https://npm.corp.example/npm" → "//npm.corp.example/npm/".
+func nerfDart(u string) string {
+	p, err := url.Parse(u)
+	if err != nil || p.Host == "" {
+		return u
+	}
+	return "//" + p.Host + strings.TrimSuffix(p.Path, "/") + "/"
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,122 @@
+package pm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestScopeTokenEnv verifies the environment variable names for scope tokens.
+func TestScopeTokenEnv(t *testing.T) {
+	for scope, want := range map[string]string{
+		"@corp":     "KB_NPM_TOKEN_CORP",
+		"@corp-int": "KB_NPM_TOKEN_CORP_INT",
+		"@Acme.io":  "KB_NPM_TOKEN_ACME_IO",
+	} {
+		if got := ScopeTokenEnv(scope); got != want {
+			t.Errorf("ScopeTokenEnv(%q) = %q, want %q", scope, got, want)
+		}
+	}
+}
+
+// TestConfigureRegistryWritesNpmrc verifies the .npmrc contents, tokens from
+// the environment and the file's permissions.
+func TestConfigureRegistryWritesNpmrc(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(TokenEnv, "main-token")
+	t.Setenv("KB_NPM_TOKEN_CORP", "corp-token")
+
+	reg, err := ConfigureRegistry(dir, "https://npm.example.com", map[string]string{"@corp": "https://npm.corp.example/npm/"})
+	if err != nil {
+		t.Fatalf("ConfigureRegistry() error = %v", err)
+	}
+	if got := strings.Join(reg.Secrets(), ","); got != "corp-token,main-token" {
+		t.Errorf("Secrets() = %q", got)
+	}
+
+	path := filepath.Join(dir, ".npmrc")
+	// #nosec G304 -- reads a file written under the test's temp dir.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"registry=https://npm.example.com\n",
+		"@corp:registry=https://npm.corp.example/npm/\n",
+		"//npm.example.com/:_authToken=main-token\n",
+		"//npm.corp.example/npm/:_authToken=corp-token\n",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf(".npmrc = %q, missing %q", data, want)
+		}
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf(".npmrc mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+}
+
+// TestConfigureRegistryKeepsStoredTokens verifies that a later run without
+// tokens in the environment keeps the stored ones, and that an empty URL
+// keeps the stored registry.
+func TestConfigureRegistryKeepsStoredTokens(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(TokenEnv, "main-token")
+	if _, err := ConfigureRegistry(dir, "https://npm.example.com", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(TokenEnv, "")
+	reg, err := ConfigureRegistry(dir, "", nil)
+	if err != nil {
+		t.Fatalf("ConfigureRegistry() error = %v", err)
+	}
+	if reg.URL != "https://npm.example.com" || reg.token(reg.URL) != "main-token" {
+		t.Errorf("ConfigureRegistry() = %+v, want stored URL and token", reg)
+	}
+}
+
+// TestConfigureRegistryNothingToWrite verifies that no .npmrc is written
+// without any registry settings.
+func TestConfigureRegistryNothingToWrite(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(TokenEnv, "main-token")
+	if _, err := ConfigureRegistry(dir, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".npmrc")); !os.IsNotExist(err) {
+		t.Errorf(".npmrc written without a registry: %v", err)
+	}
+}
+
+// TestYarnBerryUsesRegistry verifies that Yarn Berry gets the .npmrc
+// registry settings in .yarnrc.yml.
+func TestYarnBerryUsesRegistry(t *testing.T) {
+	dir, _ := fakeYarn(t, "4.5.0")
+	t.Setenv("KB_NPM_TOKEN_CORP", "corp-token")
+	if _, err := ConfigureRegistry(dir, "https://npm.example.com", map[string]string{"@corp": "https://npm.corp.example"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ch := make(chan Progress, 16)
+	if err := (&YarnManager{}).Install(context.Background(), dir, []string{"@corp/kb-audit"}, ch); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	// #nosec G304 -- reads a file written under the test's temp dir.
+	rc, err := os.ReadFile(filepath.Join(dir, ".yarnrc.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `nodeLinker: node-modules
+npmRegistryServer: "https://npm.example.com"
+npmScopes:
+  corp:
+    npmRegistryServer: "https://npm.corp.example"
+    npmAuthToken: "corp-token"
+`
+	if string(rc) != want {
+		t.Errorf(".yarnrc.yml = %q, want %q", rc, want)
+	}
+}
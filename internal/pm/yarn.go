@@ -0,0 +1,315 @@
+package pm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/kb-labs/create/internal/semver"
+)
+
+// YarnManager implements PackageManager using Yarn. Both Yarn classic (1.x)
+// and Yarn Berry (2+) are supported; the generation is detected from
+// `yarn --version`, run in the platform directory on first use.
+type YarnManager struct {
+	once  sync.Once
+	berry bool
+}
+
+func (y *YarnManager) Name() string { return "yarn" }
+
+func (y *YarnManager) Install(ctx context.Context, dir string, pkgs []string, progress chan<- Progress) error {
+	return y.run(ctx, dir, append([]string{"add"}, pkgs...), progress)
+}
+
+func (y *YarnManager) Update(ctx context.Context, dir string, pkgs []string, progress chan<- Progress) error {
+	cmd := "upgrade"
+	if y.isBerry(ctx, dir) {
+		cmd = "up"
+	}
+	return y.run(ctx, dir, append([]string{cmd}, pkgs...), progress)
+}
+
+func (y *YarnManager) Uninstall(ctx context.Context, dir string, pkgs []string, progress chan<- Progress) error {
+	return y.run(ctx, dir, append([]string{"remove"}, pkgs...), progress)
+}
+
+// ListInstalled returns the direct dependencies recorded in dir/package.json
+// with the versions Yarn resolved for them.
+func (y *YarnManager) ListInstalled(ctx context.Context, dir string) ([]InstalledPackage, error) {
+	deps, err := directDependencies(dir)
+	if err != nil || len(deps) == 0 {
+		return nil, err
+	}
+
+	args := []string{"list", "--depth=0", "--json"}
+	if y.isBerry(ctx, dir) {
+		args = []string{"info", "--json"}
+	}
+	cmd := Command(ctx, dir, "yarn", args...)
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("yarn %s: %w", args[0], err)
+	}
+
+	var all []InstalledPackage
+	if y.isBerry(ctx, dir) {
+		all, err = parseYarnBerryInfo(out)
+	} else {
+		all, err = parseYarnClassicList(out)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var pkgs []InstalledPackage
+	for _, p := range all {
+		if _, ok := deps[p.Name]; ok {
+			pkgs = append(pkgs, p)
+		}
+	}
+	return pkgs, nil
+}
+
+// Outdated runs `yarn outdated --json`, which exits 1 when anything is
+// outdated. Yarn Berry has no outdated command, so there the published
+// versions come from `yarn npm info` and are matched against the ranges in
+// package.json.
+func (y *YarnManager) Outdated(ctx context.Context, dir string, pkgs []string) ([]OutdatedPackage, error) {
+	if y.isBerry(ctx, dir) {
+		return y.berryOutdated(ctx, dir, pkgs)
+	}
+	cmd := Command(ctx, dir, "yarn", append([]string{"outdated", "--json"}, pkgs...)...)
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("yarn outdated: %w", err)
+	}
+	return parseYarnClassicOutdated(out), nil
+}
+
+// berryOutdated looks up the published versions of pkgs with
+// `yarn npm info` and reports those whose newest version in the
+// package.json range differs from the installed one. Prereleases are
+// skipped, as npm and Yarn classic skip them.
+func (y *YarnManager) berryOutdated(ctx context.Context, dir string, pkgs []string) ([]OutdatedPackage, error) {
+	ranges, err := directDependencies(dir)
+	if err != nil {
+		return nil, err
+	}
+	installed, err := y.ListInstalled(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string]string, len(installed))
+	for _, p := range installed {
+		current[p.Name] = p.Version
+	}
+
+	args := append(append([]string{"npm", "info"}, pkgs...), "--fields", "name,versions", "--json")
+	out, err := Command(ctx, dir, "yarn", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("yarn npm info: %w", err)
+	}
+	var outdated []OutdatedPackage
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var info struct {
+			Name     string   `json:"name"`
+			Versions []string `json:"versions"`
+		}
+		if err := json.Unmarshal(line, &info); err != nil {
+			return nil, fmt.Errorf("parse yarn npm info: %w", err)
+		}
+		p := OutdatedPackage{Name: info.Name, Current: current[info.Name]}
+		var wanted, latest semver.Version
+		for _, raw := range info.Versions {
+			v, err := semver.Parse(raw)
+			if err != nil || v.Pre != "" {
+				continue
+			}
+			if p.Latest == "" || semver.Compare(v, latest) > 0 {
+				p.Latest, latest = raw, v
+			}
+			if ok, err := semver.Satisfies(raw, ranges[info.Name]); err == nil && ok &&
+				(p.Wanted == "" || semver.Compare(v, wanted) > 0) {
+				p.Wanted, wanted = raw, v
+			}
+		}
+		if p.Wanted != "" && p.Wanted != p.Current {
+			outdated = append(outdated, p)
+		}
+	}
+	return outdated, nil
+}
+
+// isBerry reports whether the yarn that runs in dir is Yarn 2 or newer.
+// Projects can pin their own Yarn (packageManager, .yarnrc.yml), so the
+// answer depends on the directory.
+func (y *YarnManager) isBerry(ctx context.Context, dir string) bool {
+	y.once.Do(func() {
+		out, err := Command(ctx, dir, "yarn", "--version").Output()
+		if err == nil {
+			v := strings.TrimSpace(string(out))
+			y.berry = v != "" && !strings.HasPrefix(v, "0.") && !strings.HasPrefix(v, "1.")
+		}
+	})
+	return y.berry
+}
+
+func (y *YarnManager) run(ctx context.Context, dir string, args []string, progress chan<- Progress) error {
+	// The version check runs in dir, so it has to exist first.
+	if err := ensurePackageJSON(dir); err != nil {
+		return err
+	}
+	if y.isBerry(ctx, dir) {
+		if err := ensureBerryProject(dir); err != nil {
+			return err
+		}
+	} else {
+		args = append(args, "--non-interactive")
+	}
+	return run(ctx, dir, "yarn", args, rawLines, progress)
+}
+
+// ensureBerryProject makes dir a standalone Yarn Berry project that installs
+// into node_modules: Berry defaults to Plug'n'Play, which leaves no
+// node_modules/.bin for post-install steps, and without a yarn.lock it would
+// attach to an enclosing project instead. Berry ignores .npmrc, so the
+// registry settings ConfigureRegistry wrote there are copied into
+// .yarnrc.yml, which is rewritten on every run.
+func ensureBerryProject(dir string) error {
+	reg, err := readNpmrc(dir)
+	if err != nil {
+		return err
+	}
+	rc := "nodeLinker: node-modules\n" + reg.yarnrc()
+	if err := os.WriteFile(filepath.Join(dir, ".yarnrc.yml"), []byte(rc), 0o600); err != nil {
+		return err
+	}
+	lock := filepath.Join(dir, "yarn.lock")
+	if _, err := os.Stat(lock); os.IsNotExist(err) {
+		return os.WriteFile(lock, nil, 0o600)
+	}
+	return nil
+}
+
+// directDependencies returns the dependencies in dir/package.json, mapped
+// to their version ranges.
+func directDependencies(dir string) (map[string]string, error) {
+	// #nosec G304 -- dir is the platform directory managed by kb-create.
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var pkg struct {
+		Dependencies map[string]string `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("parse package.json: %w", err)
+	}
+	return pkg.Dependencies, nil
+}
+
+// parseYarnClassicList parses the NDJSON of `yarn list --json` (Yarn 1),
+// whose "tree" event names each package as "name@version".
+func parseYarnClassicList(out []byte) ([]InstalledPackage, error) {
+	var pkgs []InstalledPackage
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		var ev struct {
+			Type string `json:"type"`
+			Data struct {
+				Trees []struct {
+					Name string `json:"name"`
+				} `json:"trees"`
+			} `json:"data"`
+		}
+		if json.Unmarshal(line, &ev) != nil || ev.Type != "tree" {
+			continue
+		}
+		for _, tree := range ev.Data.Trees {
+			name, version := splitNameVersion(tree.Name)
+			pkgs = append(pkgs, InstalledPackage{Name: name, Version: version})
+		}
+	}
+	return pkgs, nil
+}
+
+// parseYarnClassicOutdated parses the NDJSON of `yarn outdated --json`
+// (Yarn 1), whose "table" event has one row per outdated package under the
+// column names in head.
+func parseYarnClassicOutdated(out []byte) []OutdatedPackage {
+	var pkgs []OutdatedPackage
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		var ev struct {
+			Type string `json:"type"`
+			Data struct {
+				Head []string   `json:"head"`
+				Body [][]string `json:"body"`
+			} `json:"data"`
+		}
+		if json.Unmarshal(line, &ev) != nil || ev.Type != "table" {
+			continue
+		}
+		for _, row := range ev.Data.Body {
+			pkgs = append(pkgs, outdatedRow(ev.Data.Head, row, "Wanted"))
+		}
+	}
+	return pkgs
+}
+
+// outdatedRow builds an OutdatedPackage from a table row under the column
+// names in head. wanted names the column with the version Update installs.
+func outdatedRow(head, row []string, wanted string) OutdatedPackage {
+	col := func(name string) string {
+		if i := slices.Index(head, name); i >= 0 && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+	return OutdatedPackage{Name: col("Package"), Current: col("Current"), Wanted: col(wanted), Latest: col("Latest")}
+}
+
+// parseYarnBerryInfo parses the NDJSON of `yarn info --json` (Yarn 2+),
+// one object per dependency with its locator ("name@npm:1.2.3") in value.
+func parseYarnBerryInfo(out []byte) ([]InstalledPackage, error) {
+	var pkgs []InstalledPackage
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var info struct {
+			Value    string `json:"value"`
+			Children struct {
+				Version string `json:"Version"`
+			} `json:"children"`
+		}
+		if err := json.Unmarshal(line, &info); err != nil {
+			return nil, fmt.Errorf("parse yarn info: %w", err)
+		}
+		name, ref := splitNameVersion(info.Value)
+		version := info.Children.Version
+		if version == "" {
+			version = strings.TrimPrefix(ref, "npm:")
+		}
+		pkgs = append(pkgs, InstalledPackage{Name: name, Version: version})
+	}
+	return pkgs, nil
+}
+
+// splitNameVersion splits "name@version", keeping the "@" of a scope.
+func splitNameVersion(s string) (name, version string) {
+	if i := strings.LastIndex(s, "@"); i > 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
@@ -0,0 +1,243 @@
+package pm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeYarnScript answers --version with the contents of .yarn-version in
+// the working directory, as a project-pinned Yarn would, or else with
+// $FAKE_YARN_VERSION. It prints canned list/info output and logs every
+// invocation's arguments to $FAKE_PM_LOG.
+const fakeYarnScript = `#!/bin/sh
+echo "$@" >> "$FAKE_PM_LOG"
+case "$1" in
+--version) if [ -f .yarn-version ]; then read -r v < .yarn-version; echo "$v"; else echo "$FAKE_YARN_VERSION"; fi ;;
+list)
+  echo '{"type":"info","data":"resolving"}'
+  echo '{"type":"tree","data":{"type":"list","trees":[{"name":"@kb-labs/sdk@1.2.3","children":[]},{"name":"left-pad@1.3.0","children":[]}]}}'
+  ;;
+info)
+  echo '{"value":"@kb-labs/sdk@npm:2.0.1","children":{"Version":"2.0.1"}}'
+  echo '{"value":"kb-platform@workspace:.","children":{}}'
+  ;;
+outdated)
+  echo '{"type":"info","data":"Color legend"}'
+  echo '{"type":"table","data":{"head":["Package","Current","Wanted","Latest","Package Type","URL"],"body":[["@kb-labs/sdk","1.2.3","1.4.0","2.0.0","dependencies","https://kb-labs.dev"]]}}'
+  exit 1
+  ;;
+npm)
+  echo '{"name":"@kb-labs/sdk","versions":["1.2.3","1.4.0","1.5.0-beta.1","2.0.0"]}'
+  ;;
+add) echo "added $2" ;;
+esac
+`
+
+// fakeYarn puts a fake yarn reporting version on PATH; see fakeTool.
+func fakeYarn(t *testing.T, version string) (string, func() []string) {
+	t.Helper()
+	t.Setenv("FAKE_YARN_VERSION", version)
+	return fakeTool(t, "yarn", fakeYarnScript)
+}
+
+// fakeTool puts an executable script named name on PATH, which it replaces,
+// and returns a platform dir whose package.json depends on @kb-labs/sdk, plus
+// a function returning the invocations the script logged to $FAKE_PM_LOG.
+func fakeTool(t *testing.T, name, script string) (string, func() []string) {
+	t.Helper()
+	bin := t.TempDir()
+	// #nosec G306 -- the fake binary must be executable.
+	if err := os.WriteFile(filepath.Join(bin, name), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	log := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("PATH", bin)
+	t.Setenv("FAKE_PM_LOG", log)
+
+	dir := t.TempDir()
+	pkg := `{"name":"kb-platform","private":true,"dependencies":{"@kb-labs/sdk":"^1.0.0"}}`
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(pkg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() []string {
+		// #nosec G304 -- log is a temp file written by the fake binary.
+		data, _ := os.ReadFile(log)
+		return strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+}
+
+// drain collects progress lines until the channel is closed.
+func drain(ch chan Progress) []string {
+	var lines []string
+	for p := range ch {
+		lines = append(lines, p.Line)
+	}
+	return lines
+}
+
+// TestYarnManagerName verifies YarnManager.Name returns "yarn".
+func TestYarnManagerName(t *testing.T) {
+	if got := (&YarnManager{}).Name(); got != "yarn" {
+		t.Errorf("YarnManager.Name() = %q, want \"yarn\"", got)
+	}
+}
+
+// TestDetectFindsYarn verifies that yarn is picked when it is the only
+// package manager besides npm on PATH.
+func TestDetectFindsYarn(t *testing.T) {
+	fakeYarn(t, "1.22.22")
+	if got := Detect().Name(); got != "yarn" {
+		t.Errorf("Detect() = %q, want yarn", got)
+	}
+}
+
+// TestYarnClassicCommands verifies the Yarn 1 commands and that progress
+// lines are streamed.
+func TestYarnClassicCommands(t *testing.T) {
+	dir, calls := fakeYarn(t, "1.22.22")
+	y := &YarnManager{}
+
+	ch := make(chan Progress, 16)
+	if err := y.Install(context.Background(), dir, []string{"@kb-labs/sdk@^1.0.0"}, ch); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if err := y.Update(context.Background(), dir, []string{"@kb-labs/sdk"}, ch); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := y.Uninstall(context.Background(), dir, []string{"@kb-labs/sdk"}, ch); err != nil {
+		t.Fatalf("Uninstall() error = %v", err)
+	}
+	close(ch)
+
+	if lines := drain(ch); !slices.Contains(lines, "added @kb-labs/sdk@^1.0.0") {
+		t.Errorf("progress lines = %v, want install output", lines)
+	}
+	want := []string{
+		"--version",
+		"add @kb-labs/sdk@^1.0.0 --non-interactive",
+		"upgrade @kb-labs/sdk --non-interactive",
+		"remove @kb-labs/sdk --non-interactive",
+	}
+	if got := calls(); !slices.Equal(got, want) {
+		t.Errorf("yarn calls = %q, want %q", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".yarnrc.yml")); !os.IsNotExist(err) {
+		t.Error("Yarn classic install wrote .yarnrc.yml")
+	}
+}
+
+// TestYarnBerryCommands verifies the Yarn 2+ commands and that the platform
+// dir is set up as a node_modules project.
+func TestYarnBerryCommands(t *testing.T) {
+	dir, calls := fakeYarn(t, "4.5.0")
+	y := &YarnManager{}
+
+	ch := make(chan Progress, 16)
+	if err := y.Install(context.Background(), dir, []string{"@kb-labs/sdk"}, ch); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if err := y.Update(context.Background(), dir, []string{"@kb-labs/sdk"}, ch); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	close(ch)
+	drain(ch)
+
+	want := []string{"--version", "add @kb-labs/sdk", "up @kb-labs/sdk"}
+	if got := calls(); !slices.Equal(got, want) {
+		t.Errorf("yarn calls = %q, want %q", got, want)
+	}
+	// #nosec G304 -- reads a file written under the test's temp dir.
+	rc, err := os.ReadFile(filepath.Join(dir, ".yarnrc.yml"))
+	if err != nil || !strings.Contains(string(rc), "nodeLinker: node-modules") {
+		t.Errorf(".yarnrc.yml = %q, %v; want nodeLinker: node-modules", rc, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "yarn.lock")); err != nil {
+		t.Errorf("yarn.lock not created: %v", err)
+	}
+}
+
+// TestYarnListInstalled verifies that both generations' JSON is parsed and
+// filtered to the platform's direct dependencies.
+func TestYarnListInstalled(t *testing.T) {
+	tests := []struct{ version, want string }{
+		{"1.22.22", "1.2.3"},
+		{"3.6.4", "2.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			dir, _ := fakeYarn(t, tt.version)
+			pkgs, err := (&YarnManager{}).ListInstalled(context.Background(), dir)
+			if err != nil {
+				t.Fatalf("ListInstalled() error = %v", err)
+			}
+			if len(pkgs) != 1 || pkgs[0].Name != "@kb-labs/sdk" || pkgs[0].Version != tt.want {
+				t.Errorf("ListInstalled() = %+v, want @kb-labs/sdk %s", pkgs, tt.want)
+			}
+		})
+	}
+}
+
+// TestYarnOutdated verifies that Yarn classic's outdated table is parsed
+// despite its exit status and that Yarn Berry matches the published versions
+// against the package.json range.
+func TestYarnOutdated(t *testing.T) {
+	dir, calls := fakeYarn(t, "1.22.22")
+	pkgs, err := (&YarnManager{}).Outdated(context.Background(), dir, []string{"@kb-labs/sdk"})
+	if err != nil {
+		t.Fatalf("Outdated() error = %v", err)
+	}
+	want := []OutdatedPackage{{Name: "@kb-labs/sdk", Current: "1.2.3", Wanted: "1.4.0", Latest: "2.0.0"}}
+	if !slices.Equal(pkgs, want) {
+		t.Errorf("Outdated() = %+v, want %+v", pkgs, want)
+	}
+	if got := calls(); !slices.Contains(got, "outdated --json @kb-labs/sdk") {
+		t.Errorf("yarn calls = %q, want outdated --json @kb-labs/sdk", got)
+	}
+
+	dir, calls = fakeYarn(t, "3.6.4")
+	pkgs, err = (&YarnManager{}).Outdated(context.Background(), dir, []string{"@kb-labs/sdk"})
+	if err != nil {
+		t.Fatalf("Outdated() on Yarn Berry error = %v", err)
+	}
+	want = []OutdatedPackage{{Name: "@kb-labs/sdk", Current: "2.0.1", Wanted: "1.4.0", Latest: "2.0.0"}}
+	if !slices.Equal(pkgs, want) {
+		t.Errorf("Outdated() on Yarn Berry = %+v, want %+v", pkgs, want)
+	}
+	if got := calls(); !slices.Contains(got, "npm info @kb-labs/sdk --fields name,versions --json") {
+		t.Errorf("yarn calls = %q, want npm info", got)
+	}
+}
+
+// TestYarnVersionFromPlatformDir verifies that the Yarn generation is taken
+// from the yarn that runs in the platform directory, not the caller's.
+func TestYarnVersionFromPlatformDir(t *testing.T) {
+	dir, calls := fakeYarn(t, "1.22.22")
+	if err := os.WriteFile(filepath.Join(dir, ".yarn-version"), []byte("4.5.0\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ch := make(chan Progress, 16)
+	if err := (&YarnManager{}).Update(context.Background(), dir, []string{"@kb-labs/sdk"}, ch); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	close(ch)
+	drain(ch)
+	if got := calls(); !slices.Contains(got, "up @kb-labs/sdk") {
+		t.Errorf("yarn calls = %q, want Berry's up", got)
+	}
+}
+
+// TestParseYarnBerryInfoLocatorFallback verifies that the version is taken
+// from the locator when the Version field is missing.
+func TestParseYarnBerryInfoLocatorFallback(t *testing.T) {
+	pkgs, err := parseYarnBerryInfo([]byte(`{"value":"left-pad@npm:1.3.0","children":{}}` + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 1 || pkgs[0].Name != "left-pad" || pkgs[0].Version != "1.3.0" {
+		t.Errorf("parseYarnBerryInfo() = %+v", pkgs)
+	}
+}
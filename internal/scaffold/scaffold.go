@@ -8,13 +8,23 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/kb-labs/create/internal/manifest"
 )
 
 // Options controls which sections are included in the generated config.
 type Options struct {
 	PlatformDir string
-	Services    []string // selected service IDs (e.g. "rest", "workflow")
-	Plugins     []string // selected plugin IDs  (e.g. "mind", "agents")
+	// Manifest lists every service and plugin; each gets an entry, enabled
+	// if selected. Snippets and comments come from Component.Config.
+	Manifest *manifest.Manifest
+	Services []string // selected service IDs (e.g. "rest", "workflow")
+	Plugins  []string // selected plugin IDs  (e.g. "mind", "agents")
+	// Ports overrides service ports by manifest.PortKey (e.g. "rest": 5051).
+	Ports map[string]int
+	// UserPlugins are third-party plugins outside the manifest; each gets an
+	// enabled entry after the manifest's plugins.
+	UserPlugins []manifest.Component
 }
 
 // WriteProjectConfig generates .kb/kb.config.jsonc inside projectDir.
@@ -31,6 +41,9 @@ func WriteProjectConfig(projectDir string, opts Options) error {
 }
 
 func generate(opts Options) string {
+	if opts.Manifest == nil {
+		opts.Manifest = &manifest.Manifest{}
+	}
 	svcSet := toSet(opts.Services)
 	plugSet := toSet(opts.Plugins)
 
@@ -89,31 +102,50 @@ func generate(opts Options) string {
   // Background daemons. Enable/disable based on what you installed.
   "services": {
 `)
-	writeToggle(&b, "rest", "REST API daemon on port 5050.", svcSet)
-	writeToggle(&b, "workflow", "Workflow engine on port 7778.", svcSet)
-	writeToggle(&b, "studio", "Web UI on port 3000.", svcSet)
+	for _, c := range opts.Manifest.Services {
+		if c.IsDeprecated() {
+			continue
+		}
+		writeToggle(&b, c.ID, c.ConfigDescription(), svcSet)
+	}
 	b.WriteString(`  },
 
 `)
 
+	// ── ports section ─────────────────────────────────────────────────────
+	if ports := opts.Manifest.ServicePorts(nil, opts.Ports); len(ports) > 0 {
+		b.WriteString(`  // ─── Ports ────────────────────────────────────────────────────────────
+  // Localhost ports the services listen on. Keys are the service ID for
+  // its main port, or "<service>.<name>" for additional ones.
+  "ports": {
+`)
+		defaults := opts.Manifest.ServicePorts(nil, nil)
+		for i, p := range ports {
+			fmt.Fprintf(&b, "    %s: %d,", quote(p.Key), p.Port)
+			if d := defaults[i].Port; d != p.Port {
+				fmt.Fprintf(&b, " // default %d", d)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(`  },
+
+`)
+	}
+
 	// ── plugins section ───────────────────────────────────────────────────
 	b.WriteString(`  // ─── Plugins ──────────────────────────────────────────────────────────
   // Optional functionality. Each plugin can have its own nested config.
   "plugins": {
 `)
-	writePluginBlock(&b, "mind", "AI-powered code search (RAG).", plugSet, `
-      // Vector store for embeddings.
-      // "local" = on-disk HNSW index, "qdrant" = external Qdrant server.
-      "vectorStore": "local"`)
-	writePluginBlock(&b, "agents", "Autonomous agent execution.", plugSet, `
-      // Max steps per agent run (prevents infinite loops).
-      "maxSteps": 25`)
-	writePluginBlock(&b, "ai-review", "AI code review.", plugSet, `
-      // Review mode: "heuristic" (fast), "llm" (smart), "full" (both).
-      "mode": "full"`)
-	writePluginBlock(&b, "commit", "AI-powered commit message generation.", plugSet, `
-      // Auto-stage changed files before generating commit.
-      "autoStage": false`)
+	for _, c := range opts.Manifest.Plugins {
+		if c.IsDeprecated() {
+			continue
+		}
+		writePluginBlock(&b, c.ID, c.ConfigDescription(), plugSet, c.Config.Snippet)
+	}
+	for _, c := range opts.UserPlugins {
+		writePluginBlock(&b, c.ID, c.ConfigDescription(), map[string]bool{c.ID: true}, nil)
+	}
 	b.WriteString(`  }
 }
 `)
@@ -131,18 +163,230 @@ func writeToggle(b *strings.Builder, id, comment string, enabled map[string]bool
 }
 
 // writePluginBlock writes a plugin config object with a comment and optional
-// inner settings. Disabled plugins are written commented-out style (enabled: false).
-func writePluginBlock(b *strings.Builder, id, comment string, enabled map[string]bool, inner string) {
-	on := enabled[id]
+// inner settings, one JSONC line per element of inner. Disabled plugins are
+// written commented-out style (enabled: false).
+func writePluginBlock(b *strings.Builder, id, comment string, enabled map[string]bool, inner []string) {
 	fmt.Fprintf(b, "    // %s\n", comment)
 	fmt.Fprintf(b, "    %s: {\n", quote(id))
-	if on {
-		fmt.Fprintf(b, "      \"enabled\": true,")
+	fmt.Fprintf(b, "      \"enabled\": %t", enabled[id])
+	if len(inner) > 0 {
+		b.WriteString(",\n")
+		for _, line := range inner {
+			if line == "" {
+				b.WriteString("\n")
+				continue
+			}
+			b.WriteString("      " + line + "\n")
+		}
 	} else {
-		fmt.Fprintf(b, "      \"enabled\": false,")
+		b.WriteString("\n")
+	}
+	b.WriteString("    },\n")
+}
+
+// AddPlugin adds an enabled entry for plugin id to the "plugins" section of
+// an existing projectDir/.kb/kb.config.jsonc, keeping the user's edits. An
+// existing entry is switched on with its settings kept. It reports false if
+// there is no config, no plugins section to add it to, or an existing entry
+// without an enabled flag it can flip.
+func AddPlugin(projectDir, id, comment string) (bool, error) {
+	path := filepath.Join(projectDir, ".kb", "kb.config.jsonc")
+	// #nosec G304 -- path is the project config this package generates.
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read project config: %w", err)
+	}
+	content := string(data)
+	if strings.Contains(content, quote(id)+": {") {
+		return enablePlugin(path, content, id)
+	}
+	const anchor = "  \"plugins\": {\n"
+	i := strings.Index(content, anchor)
+	if i < 0 {
+		return false, nil
+	}
+	var b strings.Builder
+	writePluginBlock(&b, id, comment, map[string]bool{id: true}, nil)
+	at := i + len(anchor)
+	content = content[:at] + b.String() + content[at:]
+	// #nosec G306 -- project config is expected to be readable in workspace.
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return false, fmt.Errorf("write project config: %w", err)
+	}
+	return true, nil
+}
+
+// enablePlugin sets the "enabled" flag of the existing block of plugin id
+// in content, rewriting path if it was off.
+func enablePlugin(path, content, id string) (bool, error) {
+	key := quote(id) + ":"
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		rest := strings.TrimLeft(line, " ")
+		if !strings.HasPrefix(rest, key) {
+			continue
+		}
+		value := strings.TrimLeft(strings.TrimPrefix(rest, key), " ")
+		if !strings.HasPrefix(value, "{") {
+			continue // a service toggle or port with the same ID
+		}
+		changed, ok := setPluginFlag(lines, i, value, true)
+		if !ok {
+			return false, nil
+		}
+		if !changed {
+			return true, nil
+		}
+		// #nosec G306 -- project config is expected to be readable in workspace.
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+			return false, fmt.Errorf("write project config: %w", err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// ReplaceComponent renames the entry of the deprecated component from to
+// to.ID in an existing projectDir/.kb/kb.config.jsonc, keeping its value
+// (enabled state, plugin settings, port) and swapping the generated comment.
+// It reports false, leaving the file alone, if there is already an entry for
+// to that the user has to merge by hand. A missing config is not an error.
+func ReplaceComponent(projectDir string, from, to manifest.Component) (bool, error) {
+	path := filepath.Join(projectDir, ".kb", "kb.config.jsonc")
+	// #nosec G304 -- path is the project config this package generates.
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read project config: %w", err)
+	}
+	oldKey, newKey := quote(from.ID)+":", quote(to.ID)+":"
+	if !strings.Contains(string(data), oldKey) {
+		return true, nil
+	}
+	if strings.Contains(string(data), newKey) {
+		return false, nil
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		rest := strings.TrimLeft(line, " ")
+		if !strings.HasPrefix(rest, oldKey) {
+			continue
+		}
+		indent := line[:len(line)-len(rest)]
+		lines[i] = indent + newKey + strings.TrimPrefix(rest, oldKey)
+		if i > 0 && strings.TrimSpace(lines[i-1]) == "// "+from.ConfigDescription() {
+			lines[i-1] = indent + "// " + to.ConfigDescription()
+		}
+	}
+	// #nosec G306 -- project config is expected to be readable in workspace.
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		return false, fmt.Errorf("write project config: %w", err)
+	}
+	return true, nil
+}
+
+// DisableComponent turns off the entry of component id in an existing
+// projectDir/.kb/kb.config.jsonc, keeping its settings: a service toggle
+// becomes false and a plugin's "enabled" becomes false. It reports false,
+// leaving the file alone, if the entry has no enabled flag it can flip. A
+// missing config or entry is not an error.
+func DisableComponent(projectDir, id string) (bool, error) {
+	path := filepath.Join(projectDir, ".kb", "kb.config.jsonc")
+	// #nosec G304 -- path is the project config this package generates.
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read project config: %w", err)
+	}
+
+	key := quote(id) + ":"
+	lines := strings.Split(string(data), "\n")
+	changed, found := false, false
+	for i := 0; i < len(lines) && !found; i++ {
+		rest := strings.TrimLeft(lines[i], " ")
+		if !strings.HasPrefix(rest, key) {
+			continue
+		}
+		value := strings.TrimLeft(strings.TrimPrefix(rest, key), " ")
+		switch {
+		case strings.HasPrefix(value, "true"):
+			// Service toggle.
+			at := len(lines[i]) - len(value)
+			lines[i] = lines[i][:at] + "false" + strings.TrimPrefix(value, "true")
+			found, changed = true, true
+		case strings.HasPrefix(value, "false"):
+			found = true
+		case strings.HasPrefix(value, "{"):
+			var ok bool
+			if changed, ok = setPluginFlag(lines, i, value, false); !ok {
+				return false, nil
+			}
+			found = true
+		}
+		// Other values (e.g. a port number) belong to another section.
+	}
+	if !changed {
+		return true, nil
+	}
+	// #nosec G306 -- project config is expected to be readable in workspace.
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		return false, fmt.Errorf("write project config: %w", err)
+	}
+	return true, nil
+}
+
+// setPluginFlag sets the "enabled" flag of the plugin block whose key is on
+// lines[i], followed by value. It reports whether it changed a line and
+// whether the block has a top-level enabled flag at all.
+func setPluginFlag(lines []string, i int, value string, enabled bool) (changed, ok bool) {
+	depth := 0
+	for j := i; j < len(lines); j++ {
+		line := lines[j]
+		if j == i {
+			line = value
+		}
+		if depth <= 1 {
+			if out, has := setFlag(line, enabled); has {
+				if out == line {
+					return false, true
+				}
+				lines[j] = strings.Replace(lines[j], line, out, 1)
+				return true, true
+			}
+		}
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		if depth <= 0 {
+			return false, false
+		}
+	}
+	return false, false
+}
+
+// setFlag returns line with its "enabled" flag set to enabled, and whether
+// line has an enabled flag at all.
+func setFlag(line string, enabled bool) (string, bool) {
+	i := strings.Index(line, `"enabled":`)
+	if i < 0 {
+		return line, false
+	}
+	from, to := "true", "false"
+	if enabled {
+		from, to = to, from
+	}
+	head, value := line[:i+len(`"enabled":`)], line[i+len(`"enabled":`):]
+	trimmed := strings.TrimLeft(value, " ")
+	if !strings.HasPrefix(trimmed, from) {
+		return line, true
 	}
-	b.WriteString(inner)
-	b.WriteString("\n    },\n")
+	return head + value[:len(value)-len(trimmed)] + to + strings.TrimPrefix(trimmed, from), true
 }
 
 func quote(s string) string {
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/kb-labs/create/internal/manifest"
 )
 
 func TestWriteProjectConfig_FullSelection(t *testing.T) {
@@ -12,6 +14,7 @@ func TestWriteProjectConfig_FullSelection(t *testing.T) {
 
 	err := WriteProjectConfig(dir, Options{
 		PlatformDir: "/home/user/kb-platform",
+		Manifest:    embeddedManifest(t),
 		Services:    []string{"rest", "workflow"},
 		Plugins:     []string{"mind", "commit"},
 	})
@@ -52,6 +55,7 @@ func TestWriteProjectConfig_EmptySelection(t *testing.T) {
 
 	err := WriteProjectConfig(dir, Options{
 		PlatformDir: "/opt/kb",
+		Manifest:    embeddedManifest(t),
 	})
 	if err != nil {
 		t.Fatalf("WriteProjectConfig() error = %v", err)
@@ -104,6 +108,7 @@ func TestWriteProjectConfig_Idempotent(t *testing.T) {
 	dir := t.TempDir()
 	opts := Options{
 		PlatformDir: "/tmp/plat",
+		Manifest:    embeddedManifest(t),
 		Services:    []string{"rest"},
 		Plugins:     []string{"mind"},
 	}
@@ -141,6 +146,7 @@ func TestGenerate_AdapterDefaults(t *testing.T) {
 func TestGenerate_PluginInnerConfig(t *testing.T) {
 	content := generate(Options{
 		PlatformDir: "/x",
+		Manifest:    embeddedManifest(t),
 		Plugins:     []string{"mind", "agents", "ai-review", "commit"},
 	})
 
@@ -151,8 +157,202 @@ func TestGenerate_PluginInnerConfig(t *testing.T) {
 	assertContains(t, content, `"autoStage"`, "commit inner config")
 }
 
+func TestGenerate_SectionsFollowManifest(t *testing.T) {
+	m := &manifest.Manifest{
+		Services: []manifest.Component{{ID: "search", Description: "Search daemon"}},
+		Plugins: []manifest.Component{
+			{ID: "lint", Config: manifest.ConfigSection{
+				Description: "Linting rules.",
+				Snippet:     []string{"// Fail on warnings.", `"strict": true`},
+			}},
+			{ID: "bare", Description: "Plugin without settings"},
+		},
+	}
+	content := generate(Options{PlatformDir: "/x", Manifest: m, Plugins: []string{"lint"}})
+
+	assertContains(t, content, "// Search daemon\n    \"search\": false,", "service from manifest")
+	assertContains(t, content, "// Linting rules.\n    \"lint\": {\n      \"enabled\": true,\n      // Fail on warnings.\n      \"strict\": true\n    },", "plugin snippet")
+	assertContains(t, content, "\"bare\": {\n      \"enabled\": false\n    },", "plugin without snippet")
+	for _, stale := range []string{`"rest"`, `"mind"`, `"vectorStore"`} {
+		if strings.Contains(content, stale) {
+			t.Errorf("unexpected %s for a component not in the manifest", stale)
+		}
+	}
+}
+
+func TestGenerate_PortsSection(t *testing.T) {
+	content := generate(Options{
+		PlatformDir: "/x",
+		Manifest:    embeddedManifest(t),
+		Services:    []string{"rest"},
+		Ports:       map[string]int{"rest": 5051},
+	})
+
+	assertContains(t, content, `"ports": {`, "ports section")
+	assertContains(t, content, `"rest": 5051, // default 5050`, "overridden port")
+	assertContains(t, content, "\"workflow\": 7778,\n", "default port")
+}
+
+func TestGenerate_UserPlugins(t *testing.T) {
+	content := generate(Options{
+		PlatformDir: "/x",
+		Manifest:    embeddedManifest(t),
+		UserPlugins: []manifest.Component{{ID: "@acme/foo", Description: manifest.UserPluginDescription}},
+	})
+
+	assertContains(t, content, "// "+manifest.UserPluginDescription+"\n    \"@acme/foo\": {", "user plugin block")
+	assertPluginEnabled(t, content, "@acme/foo", true)
+}
+
+func TestAddPlugin(t *testing.T) {
+	dir := t.TempDir()
+
+	if ok, err := AddPlugin(dir, "@acme/foo", "Foo"); ok || err != nil {
+		t.Fatalf("AddPlugin() without config = (%v, %v), want (false, nil)", ok, err)
+	}
+
+	if err := WriteProjectConfig(dir, Options{PlatformDir: "/x", Manifest: embeddedManifest(t)}); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		ok, err := AddPlugin(dir, "@acme/foo", "Foo")
+		if !ok || err != nil {
+			t.Fatalf("AddPlugin() = (%v, %v), want (true, nil)", ok, err)
+		}
+	}
+
+	content := readConfig(t, dir)
+	if n := strings.Count(content, `"@acme/foo": {`); n != 1 {
+		t.Errorf("found %d @acme/foo blocks, want 1", n)
+	}
+	assertContains(t, content, "  \"plugins\": {\n    // Foo\n    \"@acme/foo\": {", "block at top of plugins")
+	assertPluginEnabled(t, content, "@acme/foo", true)
+	assertPluginEnabled(t, content, "mind", false)
+}
+
+// TestAddPluginEnablesExistingEntry verifies that a disabled entry is
+// switched on rather than counted as already added, keeping its settings.
+func TestAddPluginEnablesExistingEntry(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteProjectConfig(dir, Options{PlatformDir: "/x", Manifest: embeddedManifest(t), Plugins: []string{"agents"}}); err != nil {
+		t.Fatal(err)
+	}
+	before := readConfig(t, dir)
+
+	for range 2 {
+		if ok, err := AddPlugin(dir, "mind", "Mind"); !ok || err != nil {
+			t.Fatalf("AddPlugin() = (%v, %v), want (true, nil)", ok, err)
+		}
+	}
+
+	content := readConfig(t, dir)
+	want := strings.Replace(before, "\"mind\": {\n      \"enabled\": false", "\"mind\": {\n      \"enabled\": true", 1)
+	if content != want {
+		t.Errorf("AddPlugin changed more than the enabled flag:\n%s", content)
+	}
+	assertPluginEnabled(t, content, "mind", true)
+}
+
+func TestReplaceComponent(t *testing.T) {
+	dir := t.TempDir()
+	m := embeddedManifest(t)
+	from, _ := m.Component("rest")
+	to := manifest.Component{ID: "gateway", Description: "API gateway"}
+
+	if ok, err := ReplaceComponent(dir, from, to); !ok || err != nil {
+		t.Fatalf("ReplaceComponent() without config = (%v, %v), want (true, nil)", ok, err)
+	}
+
+	if err := WriteProjectConfig(dir, Options{PlatformDir: "/x", Manifest: m, Services: []string{"rest"}, Ports: map[string]int{"rest": 5051}}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := ReplaceComponent(dir, from, to); !ok || err != nil {
+		t.Fatalf("ReplaceComponent() = (%v, %v), want (true, nil)", ok, err)
+	}
+
+	content := readConfig(t, dir)
+	assertContains(t, content, "// API gateway\n    \"gateway\": true,", "renamed service toggle")
+	assertContains(t, content, `"gateway": 5051, // default 5050`, "renamed port override")
+	if strings.Contains(content, `"rest"`) {
+		t.Error("old entry still present")
+	}
+
+	// An existing entry for the replacement is left for the user to merge.
+	if ok, err := ReplaceComponent(dir, manifest.Component{ID: "studio"}, to); ok || err != nil {
+		t.Errorf("ReplaceComponent() onto existing entry = (%v, %v), want (false, nil)", ok, err)
+	}
+}
+
+// TestDisableComponent verifies that service toggles and plugin blocks are
+// switched off with their settings kept, and that nothing else changes.
+func TestDisableComponent(t *testing.T) {
+	dir := t.TempDir()
+	if ok, err := DisableComponent(dir, "rest"); !ok || err != nil {
+		t.Fatalf("DisableComponent() without config = (%v, %v), want (true, nil)", ok, err)
+	}
+
+	m := embeddedManifest(t)
+	if err := WriteProjectConfig(dir, Options{
+		PlatformDir: "/x",
+		Manifest:    m,
+		Services:    []string{"rest", "workflow"},
+		Plugins:     []string{"mind", "agents"},
+		Ports:       map[string]int{"rest": 5051},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	before := readConfig(t, dir)
+
+	for _, id := range []string{"rest", "mind", "studio", "unknown"} {
+		if ok, err := DisableComponent(dir, id); !ok || err != nil {
+			t.Fatalf("DisableComponent(%q) = (%v, %v), want (true, nil)", id, ok, err)
+		}
+	}
+
+	content := readConfig(t, dir)
+	assertContains(t, content, `"rest": false,`, "disabled service toggle")
+	assertContains(t, content, `"rest": 5051,`, "port override kept")
+	assertContains(t, content, "\"mind\": {\n      \"enabled\": false,", "disabled plugin")
+	assertContains(t, content, `"workflow": true,`, "other service untouched")
+	assertContains(t, content, "\"agents\": {\n      \"enabled\": true", "other plugin untouched")
+	want := strings.Replace(before, `"rest": true,`, `"rest": false,`, 1)
+	want = strings.Replace(want, "\"mind\": {\n      \"enabled\": true", "\"mind\": {\n      \"enabled\": false", 1)
+	if content != want {
+		t.Errorf("DisableComponent changed more than the enabled flags:\n%s", content)
+	}
+}
+
+// TestDisableComponentWithoutFlag verifies that an entry without an enabled
+// flag is reported and left alone.
+func TestDisableComponentWithoutFlag(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".kb", "kb.config.jsonc")
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	content := "{\n  \"plugins\": {\n    \"mind\": {\n      \"mode\": \"full\"\n    }\n  }\n}\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := DisableComponent(dir, "mind"); ok || err != nil {
+		t.Errorf("DisableComponent() = (%v, %v), want (false, nil)", ok, err)
+	}
+	if got := readConfig(t, dir); got != content {
+		t.Errorf("config changed: %q", got)
+	}
+}
+
 // ── helpers ──────────────────────────────────────────────────────────────────
 
+func embeddedManifest(t *testing.T) *manifest.Manifest {
+	t.Helper()
+	m, err := manifest.LoadDefault()
+	if err != nil {
+		t.Fatalf("LoadDefault() error = %v", err)
+	}
+	return m
+}
+
 func readConfig(t *testing.T, projectDir string) string {
 	t.Helper()
 	// #nosec G304 -- test reads a file created under its own temp project dir.
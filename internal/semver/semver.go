@@ -0,0 +1,255 @@
+// Package semver implements the small subset of semantic versioning that
+// kb-create needs: parsing versions and checking them against npm-style
+// ranges such as ">=20", "^1.2.0", "~2.3", "18.x || >=20 <23".
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed semantic version. Build metadata is discarded.
+type Version struct {
+	Pre   string // prerelease identifiers, e.g. "beta.1"
+	Major int
+	Minor int
+	Patch int
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	return s
+}
+
+// Parse parses a full or partial version ("v20.11.1", "1.2", "3").
+// Missing minor/patch parts are zero.
+func Parse(s string) (Version, error) {
+	v, _, err := parsePartial(s)
+	return v, err
+}
+
+// Compare returns -1, 0 or 1 as a is less than, equal to, or greater than b.
+// A version with a prerelease sorts before the same version without one.
+func Compare(a, b Version) int {
+	for _, d := range [3][2]int{{a.Major, b.Major}, {a.Minor, b.Minor}, {a.Patch, b.Patch}} {
+		if d[0] != d[1] {
+			if d[0] < d[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case a.Pre == b.Pre:
+		return 0
+	case a.Pre == "":
+		return 1
+	case b.Pre == "":
+		return -1
+	}
+	return comparePre(a.Pre, b.Pre)
+}
+
+// Satisfies reports whether version matches the npm-style range rng.
+// An empty range or "*" matches everything.
+func Satisfies(version, rng string) (bool, error) {
+	v, err := Parse(version)
+	if err != nil {
+		return false, err
+	}
+	for _, set := range strings.Split(rng, "||") {
+		comps, err := parseSet(set)
+		if err != nil {
+			return false, err
+		}
+		ok := true
+		for _, c := range comps {
+			if !c.match(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ── internals ────────────────────────────────────────────────────────────────
+
+type comparator struct {
+	op string // ">=", ">", "<=", "<", "="
+	v  Version
+}
+
+func (c comparator) match(v Version) bool {
+	cmp := Compare(v, c.v)
+	switch c.op {
+	case ">=":
+		return cmp >= 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	case "<":
+		return cmp < 0
+	default:
+		return cmp == 0
+	}
+}
+
+// parseSet expands one whitespace-separated comparator set into primitive
+// comparators (>=, >, <=, <, =).
+func parseSet(set string) ([]comparator, error) {
+	var out []comparator
+	for _, tok := range strings.Fields(set) {
+		op := ""
+		for _, p := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+			if strings.HasPrefix(tok, p) {
+				op, tok = p, tok[len(p):]
+				break
+			}
+		}
+		v, parts, err := parsePartial(tok)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q: %w", set, err)
+		}
+		out = append(out, expand(op, v, parts)...)
+	}
+	return out, nil
+}
+
+// expand turns an operator applied to a (possibly partial) version into
+// primitive comparators, following npm's x-range/caret/tilde rules.
+func expand(op string, v Version, parts int) []comparator {
+	if parts == 0 { // "*", "x" or ""
+		if op == "<" || op == ">" {
+			return []comparator{{op: "<", v: Version{}}} // matches nothing
+		}
+		return nil
+	}
+	upper := bump(v, parts) // first version past the partial range
+	switch op {
+	case "^":
+		switch {
+		case v.Major > 0 || parts == 1:
+			upper = Version{Major: v.Major + 1}
+		case v.Minor > 0 || parts == 2:
+			upper = Version{Minor: v.Minor + 1}
+		default:
+			upper = Version{Patch: v.Patch + 1}
+		}
+		return []comparator{{">=", v}, {"<", upper}}
+	case "~":
+		if parts == 1 {
+			upper = Version{Major: v.Major + 1}
+		} else {
+			upper = Version{Major: v.Major, Minor: v.Minor + 1}
+		}
+		return []comparator{{">=", v}, {"<", upper}}
+	case ">=":
+		return []comparator{{">=", v}}
+	case "<":
+		return []comparator{{"<", v}}
+	case ">":
+		if parts < 3 {
+			return []comparator{{">=", upper}}
+		}
+		return []comparator{{">", v}}
+	case "<=":
+		if parts < 3 {
+			return []comparator{{"<", upper}}
+		}
+		return []comparator{{"<=", v}}
+	default: // "" or "="
+		if parts < 3 {
+			return []comparator{{">=", v}, {"<", upper}}
+		}
+		return []comparator{{"=", v}}
+	}
+}
+
+// bump returns the smallest version above every version matching the
+// partial v with the given number of specified parts.
+func bump(v Version, parts int) Version {
+	switch parts {
+	case 1:
+		return Version{Major: v.Major + 1}
+	case 2:
+		return Version{Major: v.Major, Minor: v.Minor + 1}
+	default:
+		return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+}
+
+// parsePartial parses "1", "1.2", "1.2.3-pre+build", "1.x", "*" and returns
+// the version plus how many numeric parts were specified (0-3).
+func parsePartial(s string) (Version, int, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	var v Version
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		v.Pre, s = s[i+1:], s[:i]
+	}
+	if s == "" || s == "*" || s == "x" || s == "X" {
+		return v, 0, nil
+	}
+	fields := strings.Split(s, ".")
+	if len(fields) > 3 {
+		return Version{}, 0, fmt.Errorf("invalid version %q", s)
+	}
+	nums := [3]*int{&v.Major, &v.Minor, &v.Patch}
+	parts := 0
+	for i, f := range fields {
+		if f == "x" || f == "X" || f == "*" {
+			break
+		}
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return Version{}, 0, fmt.Errorf("invalid version %q", s)
+		}
+		*nums[i] = n
+		parts++
+	}
+	return v, parts, nil
+}
+
+// comparePre compares dot-separated prerelease identifiers per semver §11.
+func comparePre(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,92 @@
+package semver
+
+import "testing"
+
+// TestParse verifies full, partial and prefixed versions.
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"1.2.3", "1.2.3"},
+		{"v20.11.1", "20.11.1"},
+		{"18", "18.0.0"},
+		{"1.2", "1.2.0"},
+		{"2.0.0-beta.1+build.5", "2.0.0-beta.1"},
+	}
+	for _, tt := range tests {
+		v, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.in, err)
+			continue
+		}
+		if v.String() != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.in, v, tt.want)
+		}
+	}
+	if _, err := Parse("one.two"); err == nil {
+		t.Error("Parse(one.two) should fail")
+	}
+}
+
+// TestCompare verifies ordering including prerelease precedence.
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.0.0-beta", "1.0.0", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+	}
+	for _, tt := range tests {
+		a, _ := Parse(tt.a)
+		b, _ := Parse(tt.b)
+		if got := Compare(a, b); got != tt.want {
+			t.Errorf("Compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// TestSatisfies verifies npm-style range operators.
+func TestSatisfies(t *testing.T) {
+	tests := []struct {
+		version, rng string
+		want         bool
+	}{
+		{"20.11.1", ">=20", true},
+		{"18.19.0", ">=20", false},
+		{"20.0.0", ">20", false},
+		{"21.0.0", ">20", true},
+		{"1.9.9", "^1.2.0", true},
+		{"2.0.0", "^1.2.0", false},
+		{"0.2.9", "^0.2.3", true},
+		{"0.3.0", "^0.2.3", false},
+		{"1.2.9", "~1.2.3", true},
+		{"1.3.0", "~1.2.3", false},
+		{"18.5.0", "18.x", true},
+		{"19.0.0", "18.x", false},
+		{"22.1.0", ">=18 <22", false},
+		{"21.9.0", ">=18 <22", true},
+		{"16.0.0", "14.x || >=16", true},
+		{"15.0.0", "14.x || >=16", false},
+		{"5.0.0", "", true},
+		{"5.0.0", "*", true},
+		{"1.2.3", "1.2.3", true},
+		{"1.2.4", "=1.2.3", false},
+		{"20.5.0", "<=20", true},
+	}
+	for _, tt := range tests {
+		got, err := Satisfies(tt.version, tt.rng)
+		if err != nil {
+			t.Errorf("Satisfies(%q, %q) error = %v", tt.version, tt.rng, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Satisfies(%q, %q) = %v, want %v", tt.version, tt.rng, got, tt.want)
+		}
+	}
+}
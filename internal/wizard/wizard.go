@@ -1,14 +1,15 @@
 // Package wizard implements the interactive Bubble Tea TUI for kb-create.
-// The wizard walks through three stages: directory inputs, component
-// selection (services + plugins), and a final confirmation screen.
+// The wizard walks through directory inputs, an optional preset picker,
+// component selection (services + plugins), and a final confirmation screen.
 // When WizardOptions.Yes is true the TUI is skipped entirely and
-// Run returns a Selection populated with manifest defaults.
+// Run returns a Selection populated with manifest (or preset) defaults.
 package wizard
 
 import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -25,6 +26,17 @@ type WizardOptions struct {
 	DefaultProjectCWD string
 	// DefaultPlatformDir pre-fills the platform directory input.
 	DefaultPlatformDir string
+	// Host, if set, greys out components whose constraints it doesn't meet.
+	Host *manifest.Host
+	// Ports pre-sets service port overrides keyed by manifest.PortKey,
+	// e.g. from --port rest=5051. They can still be changed on the options screen.
+	Ports map[string]int
+	// UserPlugins are third-party plugins from --plugin. They are always
+	// installed and listed on the confirm screen.
+	UserPlugins []manifest.Component
+	// Preset pre-selects the components of a named manifest preset instead
+	// of the per-component Default flags, and skips the preset picker.
+	Preset string
 	// Yes skips the TUI and returns defaults immediately.
 	Yes bool
 }
@@ -32,8 +44,13 @@ type WizardOptions struct {
 // Run shows the interactive wizard and returns the user's selection.
 // If opts.Yes is true, returns defaults without launching TUI.
 func Run(m *manifest.Manifest, opts WizardOptions) (*installer.Selection, error) {
+	if opts.Preset != "" {
+		if _, ok := m.Preset(opts.Preset); !ok {
+			return nil, fmt.Errorf("unknown preset %q (available: %s)", opts.Preset, strings.Join(m.PresetIDs(), ", "))
+		}
+	}
 	if opts.Yes {
-		return defaultSelection(m, opts), nil
+		return defaultSelection(m, opts)
 	}
 
 	model := newModel(m, opts)
@@ -80,29 +97,37 @@ type stage int
 
 const (
 	stageDirs    stage = iota // entering platform/project dirs
+	stagePresets              // picking a preset (first screen of options)
 	stageOptions              // choosing services & plugins
 	stageConfirm              // confirm / cancel
 )
 
 type checkItem struct {
-	id      string
-	pkg     string
-	desc    string
-	checked bool
+	id          string
+	pkg         string
+	desc        string
+	unsupported string // why the host can't install it; empty if compatible
+	checked     bool
 }
 
 type wizardModel struct {
 	manifest      *manifest.Manifest
 	errMsg        string
+	notice        string // informational message, e.g. auto-selected dependencies
 	services      []checkItem
 	plugins       []checkItem
 	platformInput textinput.Model
 	cwdInput      textinput.Model
+	portInput     textinput.Model
+	ports         map[string]int // port overrides by manifest.PortKey
+	editingPort   string         // key of the port being edited; empty if none
+	userPlugins   []manifest.Component
 	stage         stage
 	activeInput   int
 	cursor        int
 	cancelled     bool
 	confirmed     bool
+	presetChosen  bool // preset given via WizardOptions or picked already
 }
 
 func newModel(m *manifest.Manifest, opts WizardOptions) wizardModel {
@@ -127,23 +152,57 @@ func newModel(m *manifest.Manifest, opts WizardOptions) wizardModel {
 	ci.SetValue(cwd)
 	ci.Width = 50
 
-	services := make([]checkItem, len(m.Services))
-	for i, s := range m.Services {
-		services[i] = checkItem{id: s.ID, pkg: s.Pkg, desc: s.Description, checked: s.Default}
+	// Deprecated components are only kept for migrating existing installs.
+	var services, plugins []checkItem
+	for _, s := range m.Services {
+		if !s.IsDeprecated() {
+			services = append(services, newCheckItem(m, s, opts.Host))
+		}
 	}
-	plugins := make([]checkItem, len(m.Plugins))
-	for i, p := range m.Plugins {
-		plugins[i] = checkItem{id: p.ID, pkg: p.Pkg, desc: p.Description, checked: p.Default}
+	for _, p := range m.Plugins {
+		if !p.IsDeprecated() {
+			plugins = append(plugins, newCheckItem(m, p, opts.Host))
+		}
+	}
+
+	port := textinput.New()
+	port.CharLimit = 5
+	port.Width = 8
+
+	ports := make(map[string]int, len(opts.Ports))
+	for k, v := range opts.Ports {
+		ports[k] = v
 	}
 
-	return wizardModel{
+	model := wizardModel{
 		manifest:      m,
 		stage:         stageDirs,
 		platformInput: pi,
 		cwdInput:      ci,
+		portInput:     port,
+		ports:         ports,
+		userPlugins:   opts.UserPlugins,
 		services:      services,
 		plugins:       plugins,
 	}
+	if p, ok := m.Preset(opts.Preset); ok {
+		model.applyPreset(p)
+		model.presetChosen = true
+	}
+	return model
+}
+
+// newCheckItem builds a checkbox for c, unchecked and marked unsupported
+// when host doesn't meet its constraints.
+func newCheckItem(m *manifest.Manifest, c manifest.Component, host *manifest.Host) checkItem {
+	item := checkItem{id: c.ID, pkg: c.Pkg, desc: c.Description, checked: c.Default}
+	if host != nil {
+		if err := m.CheckComponent(c.ID, *host); err != nil {
+			item.unsupported = err.Error()
+			item.checked = false
+		}
+	}
+	return item
 }
 
 // ── tea.Model interface ───────────────────────────────────────────────────────
@@ -158,6 +217,9 @@ func (m wizardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 	// forward to active input
 	var cmd tea.Cmd
+	if m.editingPort != "" {
+		m.portInput, cmd = m.portInput.Update(msg)
+	}
 	if m.stage == stageDirs {
 		if m.activeInput == 0 {
 			m.platformInput, cmd = m.platformInput.Update(msg)
@@ -172,6 +234,8 @@ func (m wizardModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch m.stage {
 	case stageDirs:
 		return m.handleDirsKey(msg)
+	case stagePresets:
+		return m.handlePresetsKey(msg)
 	case stageOptions:
 		return m.handleOptionsKey(msg)
 	case stageConfirm:
@@ -202,6 +266,9 @@ func (m wizardModel) handleDirsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		m.errMsg = ""
 		m.stage = stageOptions
+		if len(m.manifest.Presets) > 0 && !m.presetChosen {
+			m.stage = stagePresets
+		}
 		m.cursor = 0
 		return m, nil
 	}
@@ -214,7 +281,36 @@ func (m wizardModel) handleDirsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// handlePresetsKey drives the preset picker. Row 0 is "custom", which keeps
+// the per-component defaults; rows 1..n map to manifest.Presets.
+func (m wizardModel) handlePresetsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		m.cancelled = true
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.manifest.Presets) {
+			m.cursor++
+		}
+	case "enter":
+		if m.cursor > 0 {
+			m.applyPreset(m.manifest.Presets[m.cursor-1])
+		}
+		m.presetChosen = true
+		m.stage = stageOptions
+		m.cursor = 0
+	}
+	return m, nil
+}
+
 func (m wizardModel) handleOptionsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.editingPort != "" {
+		return m.handlePortKey(msg)
+	}
 	total := len(m.services) + len(m.plugins)
 	switch msg.String() {
 	case "ctrl+c", "esc":
@@ -230,12 +326,82 @@ func (m wizardModel) handleOptionsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	case " ":
 		m.toggleCursor()
+	case "p":
+		return m, m.editCursorPort()
 	case "enter":
 		m.stage = stageConfirm
 	}
 	return m, nil
 }
 
+// handlePortKey drives the inline port editor opened with "p".
+func (m wizardModel) handlePortKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.cancelled = true
+		return m, tea.Quit
+	case "esc":
+		m.editingPort, m.errMsg = "", ""
+		m.portInput.Blur()
+		return m, nil
+	case "enter":
+		if err := m.savePort(); err != nil {
+			m.errMsg = err.Error()
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.portInput, cmd = m.portInput.Update(msg)
+	return m, cmd
+}
+
+// editCursorPort opens the port editor for the main port of the service
+// under the cursor. Additional named ports can only be set with --port.
+func (m *wizardModel) editCursorPort() tea.Cmd {
+	if m.cursor >= len(m.services) {
+		return nil
+	}
+	ports := m.manifest.ServicePorts([]string{m.services[m.cursor].id}, m.ports)
+	if len(ports) == 0 {
+		m.errMsg = m.services[m.cursor].id + " has no ports"
+		return nil
+	}
+	m.errMsg, m.notice = "", ""
+	m.editingPort = ports[0].Key
+	m.portInput.SetValue(strconv.Itoa(ports[0].Port))
+	m.portInput.CursorEnd()
+	m.portInput.Focus()
+	return textinput.Blink
+}
+
+// savePort validates the editor value and records it as an override, or
+// drops the override when it matches the manifest default.
+func (m *wizardModel) savePort() error {
+	n, err := strconv.Atoi(strings.TrimSpace(m.portInput.Value()))
+	if err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("port must be a number between 1 and 65535")
+	}
+	key := m.editingPort
+	ports := make(map[string]int, len(m.ports)+1)
+	for k, v := range m.ports {
+		if k != key {
+			ports[k] = v
+		}
+	}
+	for _, p := range m.manifest.ServicePorts(nil, nil) {
+		if p.Key == key && p.Port != n {
+			ports[key] = n
+		}
+	}
+	if err := m.manifest.CheckPortOverrides(ports); err != nil {
+		return err
+	}
+	m.ports = ports
+	m.editingPort, m.errMsg = "", ""
+	m.portInput.Blur()
+	return nil
+}
+
 func (m wizardModel) handleConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c", "esc", "n", "N":
@@ -248,13 +414,103 @@ func (m wizardModel) handleConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// toggleCursor flips the item under the cursor. Checking an item also checks
+// everything it requires; unchecking is refused while a checked item still
+// requires it, and checking is refused if it conflicts with a checked item.
 func (m *wizardModel) toggleCursor() {
+	var item *checkItem
 	if m.cursor < len(m.services) {
-		m.services[m.cursor].checked = !m.services[m.cursor].checked
+		item = &m.services[m.cursor]
 	} else {
-		i := m.cursor - len(m.services)
-		m.plugins[i].checked = !m.plugins[i].checked
+		item = &m.plugins[m.cursor-len(m.services)]
+	}
+	m.errMsg, m.notice = "", ""
+	checked := m.checkedIDs()
+
+	if item.checked {
+		if by := m.manifest.RequiredBy(item.id, checked); len(by) > 0 {
+			m.errMsg = fmt.Sprintf("%s can't be unchecked: required by %s", item.id, strings.Join(by, ", "))
+			return
+		}
+		item.checked = false
+		return
+	}
+
+	resolved, err := m.manifest.Resolve(append(checked, item.id))
+	if err != nil {
+		m.errMsg = err.Error()
+		return
 	}
+	for _, id := range resolved {
+		if it := m.item(id); it != nil && it.unsupported != "" {
+			m.errMsg = it.unsupported
+			return
+		}
+	}
+	var added []string
+	for _, id := range resolved {
+		it := m.item(id)
+		if it == nil || it.checked {
+			continue
+		}
+		it.checked = true
+		if id != item.id {
+			added = append(added, id)
+		}
+	}
+	if len(added) > 0 {
+		m.notice = fmt.Sprintf("also selected %s (required by %s)", strings.Join(added, ", "), item.id)
+	}
+}
+
+// applyPreset checks exactly the preset's components plus whatever they
+// require, so the options screen starts from a consistent selection.
+func (m *wizardModel) applyPreset(p manifest.Preset) {
+	ids := append(append([]string{}, p.Services...), p.Plugins...)
+	if resolved, err := m.manifest.Resolve(ids); err == nil {
+		ids = resolved
+	}
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+	for i := range m.services {
+		m.services[i].checked = want[m.services[i].id] && m.services[i].unsupported == ""
+	}
+	for i := range m.plugins {
+		m.plugins[i].checked = want[m.plugins[i].id] && m.plugins[i].unsupported == ""
+	}
+}
+
+// item returns the service or plugin checkbox with the given ID, or nil.
+func (m *wizardModel) item(id string) *checkItem {
+	for i := range m.services {
+		if m.services[i].id == id {
+			return &m.services[i]
+		}
+	}
+	for i := range m.plugins {
+		if m.plugins[i].id == id {
+			return &m.plugins[i]
+		}
+	}
+	return nil
+}
+
+// checkedIDs returns the IDs of all checked services and plugins.
+func (m wizardModel) checkedIDs() []string {
+	var ids []string
+	for _, s := range m.services {
+		if s.checked {
+			ids = append(ids, s.id)
+		}
+	}
+	for _, p := range m.plugins {
+		if p.checked {
+			ids = append(ids, p.id)
+		}
+	}
+	return ids
 }
 
 func (m wizardModel) validateDirs() error {
@@ -273,6 +529,8 @@ func (m wizardModel) View() string {
 	switch m.stage {
 	case stageDirs:
 		return m.viewDirs()
+	case stagePresets:
+		return m.viewPresets()
 	case stageOptions:
 		return m.viewOptions()
 	case stageConfirm:
@@ -301,6 +559,29 @@ func (m wizardModel) viewDirs() string {
 	return b.String()
 }
 
+func (m wizardModel) viewPresets() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("  kb-create") + "  choose a preset\n\n")
+
+	rows := []checkItem{{id: "custom", desc: "Start from the default components"}}
+	for _, p := range m.manifest.Presets {
+		rows = append(rows, checkItem{id: p.ID, desc: p.Description})
+	}
+	for i, r := range rows {
+		cursor := "  "
+		style := normalStyle
+		if i == m.cursor {
+			cursor = focusStyle.Render(" ▶")
+			style = selectedStyle
+		}
+		_, _ = fmt.Fprintf(&b, "%s %-15s  %s\n", cursor, style.Render(r.id), dimStyle.Render(r.desc))
+	}
+	b.WriteString("\n")
+
+	b.WriteString(helpStyle.Render("  ↑↓ move · enter choose (fine-tune next) · esc quit"))
+	return b.String()
+}
+
 func (m wizardModel) viewOptions() string {
 	var b strings.Builder
 	b.WriteString(titleStyle.Render("  kb-create") + "  select components\n\n")
@@ -327,7 +608,20 @@ func (m wizardModel) viewOptions() string {
 	}
 	b.WriteString("\n")
 
-	b.WriteString(helpStyle.Render("  ↑↓ move · space toggle · enter install · esc quit"))
+	if m.editingPort != "" {
+		b.WriteString("  " + sectionStyle.Render("Port for "+m.editingPort) + "  " + m.portInput.View() + "\n\n")
+	}
+	if m.errMsg != "" {
+		b.WriteString("  " + errorStyle.Render("✖ "+m.errMsg) + "\n\n")
+	} else if m.notice != "" {
+		b.WriteString("  " + dimStyle.Render("ℹ "+m.notice) + "\n\n")
+	}
+
+	if m.editingPort != "" {
+		b.WriteString(helpStyle.Render("  enter save · esc cancel"))
+		return b.String()
+	}
+	b.WriteString(helpStyle.Render("  ↑↓ move · space toggle · p set port · enter install · esc quit"))
 	return b.String()
 }
 
@@ -338,14 +632,25 @@ func (m wizardModel) renderItem(idx int, item checkItem) string {
 	}
 	check := "○"
 	style := normalStyle
-	if item.checked {
+	desc := item.desc
+	switch {
+	case item.unsupported != "":
+		check = dimStyle.Render("⊘")
+		style = dimStyle
+		desc = item.unsupported
+	case item.checked:
 		check = selectedStyle.Render("◉")
 		style = selectedStyle
 	}
+	if item.unsupported == "" {
+		if ports := m.portLabels(item.id); len(ports) > 0 {
+			desc += " · port " + strings.Join(ports, ", ")
+		}
+	}
 	return fmt.Sprintf("%s %s  %-15s  %s\n",
 		cursor, check,
 		style.Render(item.id),
-		dimStyle.Render(item.desc),
+		dimStyle.Render(desc),
 	)
 }
 
@@ -366,9 +671,23 @@ func (m wizardModel) viewConfirm() string {
 			selected = append(selected, p.id)
 		}
 	}
+	for _, c := range m.userPlugins {
+		selected = append(selected, c.Spec())
+	}
 	if len(selected) > 0 {
 		b.WriteString("  Components: " + strings.Join(selected, ", ") + "\n\n")
 	}
+	if len(m.ports) > 0 {
+		var ports []string
+		for _, p := range m.manifest.ServicePorts(m.checkedIDs(), m.ports) {
+			if _, ok := m.ports[p.Key]; ok {
+				ports = append(ports, fmt.Sprintf("%s=%d", p.Key, p.Port))
+			}
+		}
+		if len(ports) > 0 {
+			b.WriteString("  Ports:      " + strings.Join(ports, ", ") + "\n\n")
+		}
+	}
 
 	b.WriteString(helpStyle.Render("  Press enter to install · n to cancel"))
 	return b.String()
@@ -376,6 +695,20 @@ func (m wizardModel) viewConfirm() string {
 
 // ── helpers ───────────────────────────────────────────────────────────────────
 
+// portLabels returns the effective ports of service id, with named ports
+// shown as "name 9090".
+func (m wizardModel) portLabels(id string) []string {
+	var out []string
+	for _, p := range m.manifest.ServicePorts([]string{id}, m.ports) {
+		label := strconv.Itoa(p.Port)
+		if name, ok := strings.CutPrefix(p.Key, id+"."); ok {
+			label = name + " " + label
+		}
+		out = append(out, label)
+	}
+	return out
+}
+
 func (m wizardModel) toSelection() *installer.Selection {
 	var services, plugins []string
 	for _, s := range m.services {
@@ -393,10 +726,16 @@ func (m wizardModel) toSelection() *installer.Selection {
 		ProjectCWD:  expandHome(m.cwdInput.Value()),
 		Services:    services,
 		Plugins:     plugins,
+		Ports:       portOverrides(m.ports),
+		UserPlugins: m.userPlugins,
 	}
 }
 
-func defaultSelection(m *manifest.Manifest, opts WizardOptions) *installer.Selection {
+// defaultSelection returns the selection of a silent install: the preset's
+// components, or else the Default ones the host can run. Components the
+// preset asks for by name are not dropped; if the host can't run one of
+// them, the install is refused with its constraint.
+func defaultSelection(m *manifest.Manifest, opts WizardOptions) (*installer.Selection, error) {
 	home, _ := os.UserHomeDir()
 	platformDir := opts.DefaultPlatformDir
 	if platformDir == "" {
@@ -408,14 +747,34 @@ func defaultSelection(m *manifest.Manifest, opts WizardOptions) *installer.Selec
 	}
 
 	var services, plugins []string
-	for _, s := range m.Services {
-		if s.Default {
-			services = append(services, s.ID)
+	if p, ok := m.Preset(opts.Preset); ok {
+		services = append(services, p.Services...)
+		plugins = append(plugins, p.Plugins...)
+		if opts.Host != nil {
+			var problems []string
+			for _, id := range append(append([]string{}, services...), plugins...) {
+				if err := m.CheckComponent(id, *opts.Host); err != nil {
+					problems = append(problems, err.Error())
+				}
+			}
+			if len(problems) > 0 {
+				return nil, fmt.Errorf("preset %q: %s", p.ID, strings.Join(problems, "; "))
+			}
 		}
-	}
-	for _, p := range m.Plugins {
-		if p.Default {
-			plugins = append(plugins, p.ID)
+	} else {
+		for _, s := range m.Services {
+			if s.Default {
+				services = append(services, s.ID)
+			}
+		}
+		for _, p := range m.Plugins {
+			if p.Default {
+				plugins = append(plugins, p.ID)
+			}
+		}
+		if opts.Host != nil {
+			services = supportedOnly(m, services, *opts.Host)
+			plugins = supportedOnly(m, plugins, *opts.Host)
 		}
 	}
 	return &installer.Selection{
@@ -423,7 +782,33 @@ func defaultSelection(m *manifest.Manifest, opts WizardOptions) *installer.Selec
 		ProjectCWD:  expandHome(cwd),
 		Services:    services,
 		Plugins:     plugins,
+		Ports:       portOverrides(opts.Ports),
+		UserPlugins: opts.UserPlugins,
+	}, nil
+}
+
+// portOverrides returns a copy of ports, or nil if there are none.
+func portOverrides(ports map[string]int) map[string]int {
+	if len(ports) == 0 {
+		return nil
+	}
+	out := make(map[string]int, len(ports))
+	for k, v := range ports {
+		out[k] = v
+	}
+	return out
+}
+
+// supportedOnly drops IDs whose constraints host doesn't meet, so silent
+// installs skip incompatible defaults instead of failing.
+func supportedOnly(m *manifest.Manifest, ids []string, host manifest.Host) []string {
+	var out []string
+	for _, id := range ids {
+		if m.CheckComponent(id, host) == nil {
+			out = append(out, id)
+		}
 	}
+	return out
 }
 
 func expandHome(path string) string {
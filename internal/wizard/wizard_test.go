@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/kb-labs/create/internal/manifest"
 )
@@ -40,7 +41,10 @@ func sampleManifest() *manifest.Manifest {
 // appear in the selection when no overrides are provided.
 func TestDefaultSelectionPicksDefaults(t *testing.T) {
 	m := sampleManifest()
-	sel := defaultSelection(m, WizardOptions{})
+	sel, err := defaultSelection(m, WizardOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if len(sel.Services) != 1 || sel.Services[0] != "rest" {
 		t.Errorf("Services = %v, want [rest]", sel.Services)
@@ -54,7 +58,10 @@ func TestDefaultSelectionPicksDefaults(t *testing.T) {
 // is used when set.
 func TestDefaultSelectionPlatformDirOverride(t *testing.T) {
 	m := sampleManifest()
-	sel := defaultSelection(m, WizardOptions{DefaultPlatformDir: "/custom/platform"})
+	sel, err := defaultSelection(m, WizardOptions{DefaultPlatformDir: "/custom/platform"})
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if sel.PlatformDir != "/custom/platform" {
 		t.Errorf("PlatformDir = %q, want %q", sel.PlatformDir, "/custom/platform")
@@ -65,7 +72,10 @@ func TestDefaultSelectionPlatformDirOverride(t *testing.T) {
 // is used when set.
 func TestDefaultSelectionCWDOverride(t *testing.T) {
 	m := sampleManifest()
-	sel := defaultSelection(m, WizardOptions{DefaultProjectCWD: "/custom/project"})
+	sel, err := defaultSelection(m, WizardOptions{DefaultProjectCWD: "/custom/project"})
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	if sel.ProjectCWD != "/custom/project" {
 		t.Errorf("ProjectCWD = %q, want %q", sel.ProjectCWD, "/custom/project")
@@ -76,7 +86,10 @@ func TestDefaultSelectionCWDOverride(t *testing.T) {
 // given, PlatformDir is under home and ProjectCWD is the current directory.
 func TestDefaultSelectionFallsBackToHomeAndCWD(t *testing.T) {
 	m := sampleManifest()
-	sel := defaultSelection(m, WizardOptions{})
+	sel, err := defaultSelection(m, WizardOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	home, _ := os.UserHomeDir()
 	if !strings.HasPrefix(sel.PlatformDir, home) {
@@ -96,7 +109,10 @@ func TestDefaultSelectionNoDefaults(t *testing.T) {
 		Services: []manifest.Component{{ID: "rest", Default: false}},
 		Plugins:  []manifest.Component{{ID: "mind", Default: false}},
 	}
-	sel := defaultSelection(m, WizardOptions{})
+	sel, err := defaultSelection(m, WizardOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
 	if len(sel.Services) != 0 {
 		t.Errorf("Services = %v, want []", sel.Services)
 	}
@@ -226,3 +242,268 @@ func TestToSelectionAllChecked(t *testing.T) {
 		t.Errorf("Plugins len = %d, want 2", len(sel.Plugins))
 	}
 }
+
+// ── toggleCursor ─────────────────────────────────────────────────────────────
+
+// depsModel returns a model where plugin "agents" requires service "workflow"
+// and service "studio" conflicts with "agents".
+func depsModel() wizardModel {
+	m := &manifest.Manifest{
+		Services: []manifest.Component{
+			{ID: "workflow", Pkg: "@kb-labs/workflow-runtime"},
+			{ID: "studio", Pkg: "@kb-labs/studio", Conflicts: []string{"agents"}},
+		},
+		Plugins: []manifest.Component{
+			{ID: "agents", Pkg: "@kb-labs/agents", Requires: []string{"workflow"}},
+		},
+	}
+	return newModel(m, WizardOptions{DefaultPlatformDir: "/p", DefaultProjectCWD: "/c"})
+}
+
+// TestToggleCursorChecksRequirements verifies that checking an item also
+// checks the components it requires.
+func TestToggleCursorChecksRequirements(t *testing.T) {
+	m := depsModel()
+	m.cursor = 2 // agents
+
+	m.toggleCursor()
+
+	if !m.plugins[0].checked {
+		t.Fatal("agents not checked")
+	}
+	if !m.services[0].checked {
+		t.Error("workflow not auto-checked as a requirement of agents")
+	}
+	if !strings.Contains(m.notice, "workflow") {
+		t.Errorf("notice = %q, want mention of workflow", m.notice)
+	}
+}
+
+// TestToggleCursorRefusesRequiredUncheck verifies that a component required
+// by a checked item cannot be unchecked and the reason is shown.
+func TestToggleCursorRefusesRequiredUncheck(t *testing.T) {
+	m := depsModel()
+	m.cursor = 2
+	m.toggleCursor() // check agents (+ workflow)
+
+	m.cursor = 0 // workflow
+	m.toggleCursor()
+
+	if !m.services[0].checked {
+		t.Error("workflow was unchecked while agents requires it")
+	}
+	if !strings.Contains(m.errMsg, "required by agents") {
+		t.Errorf("errMsg = %q, want explanation", m.errMsg)
+	}
+}
+
+// TestToggleCursorRefusesConflict verifies that checking a component that
+// conflicts with a checked one is refused.
+func TestToggleCursorRefusesConflict(t *testing.T) {
+	m := depsModel()
+	m.cursor = 2
+	m.toggleCursor() // check agents
+
+	m.cursor = 1 // studio
+	m.toggleCursor()
+
+	if m.services[1].checked {
+		t.Error("studio checked despite conflicting with agents")
+	}
+	if !strings.Contains(m.errMsg, "conflict") {
+		t.Errorf("errMsg = %q, want conflict explanation", m.errMsg)
+	}
+}
+
+// TestNewModelDisablesUnsupported verifies that a default component the host
+// can't run starts unchecked and cannot be checked.
+func TestNewModelDisablesUnsupported(t *testing.T) {
+	mf := sampleManifest()
+	mf.Plugins[0].Constraints = manifest.Constraints{OS: []string{"darwin"}} // mind
+	host := manifest.Host{OS: "linux", Arch: "amd64", Node: "20.11.0"}
+	m := newModel(mf, WizardOptions{DefaultPlatformDir: "/p", DefaultProjectCWD: "/c", Host: &host})
+
+	if m.plugins[0].checked {
+		t.Fatal("unsupported default mind is checked")
+	}
+	m.cursor = 2 // mind
+	m.toggleCursor()
+	if m.plugins[0].checked {
+		t.Error("unsupported mind could be checked")
+	}
+	if !strings.Contains(m.errMsg, "not supported on linux") {
+		t.Errorf("errMsg = %q, want constraint explanation", m.errMsg)
+	}
+}
+
+// TestDefaultSelectionSkipsUnsupported verifies that silent installs drop
+// default components the host can't run.
+func TestDefaultSelectionSkipsUnsupported(t *testing.T) {
+	mf := sampleManifest()
+	mf.Services[0].Constraints = manifest.Constraints{Node: ">=22"} // rest
+	host := manifest.Host{OS: "linux", Arch: "amd64", Node: "20.11.0"}
+	sel, err := defaultSelection(mf, WizardOptions{Host: &host})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sel.Services) != 0 {
+		t.Errorf("Services = %v, want none", sel.Services)
+	}
+	if len(sel.Plugins) != 1 || sel.Plugins[0] != "mind" {
+		t.Errorf("Plugins = %v, want [mind]", sel.Plugins)
+	}
+}
+
+// TestDefaultSelectionRefusesUnsupportedPreset verifies that a preset
+// component the host can't run fails the silent install instead of being
+// dropped.
+func TestDefaultSelectionRefusesUnsupportedPreset(t *testing.T) {
+	mf := presetManifest()
+	mf.Services[1].Constraints = manifest.Constraints{OS: []string{"darwin"}} // studio
+	host := manifest.Host{OS: "linux", Arch: "amd64", Node: "20.11.0"}
+
+	_, err := defaultSelection(mf, WizardOptions{Host: &host, Preset: "full"})
+	if err == nil || !strings.Contains(err.Error(), `preset "full": studio`) {
+		t.Errorf("defaultSelection() error = %v, want studio's constraint", err)
+	}
+	if _, err := defaultSelection(mf, WizardOptions{Host: &host, Preset: "minimal"}); err != nil {
+		t.Errorf("defaultSelection(minimal) error = %v", err)
+	}
+}
+
+// ── ports ────────────────────────────────────────────────────────────────────
+
+// TestPortEditorSetsOverride verifies that "p" on a service edits its main
+// port and the override reaches the selection.
+func TestPortEditorSetsOverride(t *testing.T) {
+	mf := sampleManifest()
+	mf.Services[0].Ports = []manifest.Port{{Port: 5050}} // rest
+	m := newModel(mf, WizardOptions{DefaultPlatformDir: "/p", DefaultProjectCWD: "/c"})
+	m.stage = stageOptions
+
+	next, _ := m.handleOptionsKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = next.(wizardModel)
+	if m.editingPort != "rest" || m.portInput.Value() != "5050" {
+		t.Fatalf("editingPort = %q, value = %q; want rest, 5050", m.editingPort, m.portInput.Value())
+	}
+	m.portInput.SetValue("5051")
+	next, _ = m.handleOptionsKey(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(wizardModel)
+
+	if m.editingPort != "" {
+		t.Error("editor still open after enter")
+	}
+	if sel := m.toSelection(); sel.Ports["rest"] != 5051 {
+		t.Errorf("Selection.Ports = %v, want rest=5051", sel.Ports)
+	}
+}
+
+// TestPortEditorRejectsInvalid verifies that a non-numeric port keeps the
+// editor open with an error.
+func TestPortEditorRejectsInvalid(t *testing.T) {
+	mf := sampleManifest()
+	mf.Services[0].Ports = []manifest.Port{{Port: 5050}}
+	m := newModel(mf, WizardOptions{DefaultPlatformDir: "/p", DefaultProjectCWD: "/c"})
+	m.stage = stageOptions
+	m.editCursorPort()
+
+	m.portInput.SetValue("http")
+	next, _ := m.handleOptionsKey(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(wizardModel)
+
+	if m.editingPort == "" || m.errMsg == "" {
+		t.Errorf("editingPort = %q, errMsg = %q; want editor open with error", m.editingPort, m.errMsg)
+	}
+	if len(m.ports) != 0 {
+		t.Errorf("ports = %v, want no override", m.ports)
+	}
+}
+
+// TestPortEditorRejectsTakenPort verifies that a port another service
+// listens on keeps the editor open with an error.
+func TestPortEditorRejectsTakenPort(t *testing.T) {
+	mf := sampleManifest()
+	mf.Services[0].Ports = []manifest.Port{{Port: 5050}}
+	mf.Services[1].Ports = []manifest.Port{{Port: 3000}}
+	m := newModel(mf, WizardOptions{DefaultPlatformDir: "/p", DefaultProjectCWD: "/c"})
+	m.stage = stageOptions
+	m.editCursorPort()
+
+	m.portInput.SetValue("3000")
+	next, _ := m.handleOptionsKey(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(wizardModel)
+
+	if m.editingPort == "" || !strings.Contains(m.errMsg, "already used by studio") {
+		t.Errorf("editingPort = %q, errMsg = %q; want editor open with error", m.editingPort, m.errMsg)
+	}
+	if len(m.ports) != 0 {
+		t.Errorf("ports = %v, want no override", m.ports)
+	}
+}
+
+// ── presets ──────────────────────────────────────────────────────────────────
+
+// presetManifest returns sampleManifest with two presets.
+func presetManifest() *manifest.Manifest {
+	m := sampleManifest()
+	m.Presets = []manifest.Preset{
+		{ID: "minimal", Services: []string{"rest"}},
+		{ID: "full", Services: []string{"rest", "studio"}, Plugins: []string{"mind", "agents"}},
+	}
+	return m
+}
+
+// TestDefaultSelectionUsesPreset verifies that a preset replaces the
+// per-component Default flags.
+func TestDefaultSelectionUsesPreset(t *testing.T) {
+	sel, err := defaultSelection(presetManifest(), WizardOptions{Preset: "full"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sel.Services) != 2 || len(sel.Plugins) != 2 {
+		t.Errorf("Selection = %v / %v, want full preset", sel.Services, sel.Plugins)
+	}
+}
+
+// TestRunUnknownPreset verifies that an unknown preset is rejected up front.
+func TestRunUnknownPreset(t *testing.T) {
+	_, err := Run(presetManifest(), WizardOptions{Yes: true, Preset: "nope"})
+	if err == nil || !strings.Contains(err.Error(), "minimal") {
+		t.Errorf("Run() error = %v, want unknown preset listing available ones", err)
+	}
+}
+
+// TestNewModelAppliesPreset verifies that WizardOptions.Preset pre-checks the
+// preset's components and skips the picker.
+func TestNewModelAppliesPreset(t *testing.T) {
+	m := newModel(presetManifest(), WizardOptions{Preset: "minimal"})
+
+	sel := m.toSelection()
+	if len(sel.Services) != 1 || sel.Services[0] != "rest" || len(sel.Plugins) != 0 {
+		t.Errorf("Selection = %v / %v, want [rest] / []", sel.Services, sel.Plugins)
+	}
+	if !m.presetChosen {
+		t.Error("presetChosen = false, want picker skipped")
+	}
+}
+
+// TestPresetPickerApplies verifies that choosing a preset in the picker checks
+// its components and moves on to the options screen.
+func TestPresetPickerApplies(t *testing.T) {
+	m := newModel(presetManifest(), WizardOptions{})
+	m.stage = stagePresets
+	m.cursor = 2 // "full" (row 0 is custom)
+
+	next, _ := m.handlePresetsKey(tea.KeyMsg{Type: tea.KeyEnter})
+	got := next.(wizardModel)
+
+	if got.stage != stageOptions {
+		t.Errorf("stage = %v, want stageOptions", got.stage)
+	}
+	sel := got.toSelection()
+	if len(sel.Services) != 2 || len(sel.Plugins) != 2 {
+		t.Errorf("Selection = %v / %v, want full preset", sel.Services, sel.Plugins)
+	}
+}